@@ -0,0 +1,142 @@
+package restapi
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Client 通用的 Token 认证 REST 客户端，供只需要 API Token 的 DNS 提供商复用
+type Client struct {
+	client *resty.Client
+}
+
+// Option 客户端配置项
+type Option func(*resty.Client)
+
+// WithBearerToken 使用 Authorization: Bearer <token> 认证
+func WithBearerToken(token string) Option {
+	return func(c *resty.Client) {
+		c.SetAuthToken(token)
+	}
+}
+
+// WithHeader 使用自定义请求头认证，如 Hetzner 的 Auth-API-Token
+func WithHeader(key, value string) Option {
+	return func(c *resty.Client) {
+		c.SetHeader(key, value)
+	}
+}
+
+// WithBasicAuth 使用 Basic Auth 认证
+func WithBasicAuth(username, password string) Option {
+	return func(c *resty.Client) {
+		c.SetBasicAuth(username, password)
+	}
+}
+
+// WithQueryParam 使用查询参数认证，如 Technitium 的 token
+func WithQueryParam(key, value string) Option {
+	return func(c *resty.Client) {
+		c.SetQueryParam(key, value)
+	}
+}
+
+// WithInsecureSkipVerify 跳过证书校验，用于使用自签名证书的内网服务
+func WithInsecureSkipVerify() Option {
+	return func(c *resty.Client) {
+		c.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	}
+}
+
+// WithTimeout 设置请求超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *resty.Client) {
+		c.SetTimeout(timeout)
+	}
+}
+
+// WithXMLResponse 将响应按 XML 解析，用于 Namecheap 等返回 XML 的接口
+func WithXMLResponse() Option {
+	return func(c *resty.Client) {
+		c.SetHeader("Accept", "application/xml")
+		c.OnBeforeRequest(func(_ *resty.Client, r *resty.Request) error {
+			r.ForceContentType("application/xml")
+			return nil
+		})
+	}
+}
+
+// WithJSONResponse 无论响应的 Content-Type 是什么都按 JSON 解析，用于未正确设置响应头的内部接口
+func WithJSONResponse() Option {
+	return func(c *resty.Client) {
+		c.OnBeforeRequest(func(_ *resty.Client, r *resty.Request) error {
+			r.ForceContentType("application/json")
+			return nil
+		})
+	}
+}
+
+// NewClient 初始化客户端
+func NewClient(baseURL string, options ...Option) (*Client, error) {
+	if baseURL == "" {
+		return nil, errors.New("missing API base url")
+	}
+	c := resty.New().SetBaseURL(baseURL).
+		SetHeader("Accept", "application/json").
+		SetTimeout(10 * time.Second).SetRetryCount(3).SetRetryWaitTime(2 * time.Second)
+	for _, option := range options {
+		option(c)
+	}
+	return &Client{client: c}, nil
+}
+
+// Get 发起 GET 请求，并将响应体解析到 result 中
+func (c *Client) Get(path string, params url.Values, result interface{}) error {
+	resp, err := c.client.R().
+		SetQueryParamsFromValues(params).
+		SetResult(result).
+		Get(path)
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode(), resp.String())
+	}
+	return nil
+}
+
+// Post 发起 POST 请求，body 会被序列化为 JSON，并将响应体解析到 result 中
+func (c *Client) Post(path string, body interface{}, result interface{}) error {
+	resp, err := c.client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(body).
+		SetResult(result).
+		Post(path)
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode(), resp.String())
+	}
+	return nil
+}
+
+// PostForm 发起表单 POST 请求，并将响应体解析到 result 中，用于获取 OAuth Token 等场景
+func (c *Client) PostForm(path string, form url.Values, result interface{}) error {
+	resp, err := c.client.R().
+		SetFormDataFromValues(form).
+		SetResult(result).
+		Post(path)
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode(), resp.String())
+	}
+	return nil
+}
@@ -9,6 +9,8 @@ import (
 	"runtime"
 
 	"github.com/eryajf/cloud_dns_exporter/pkg/export"
+	"github.com/eryajf/cloud_dns_exporter/pkg/notify"
+	"github.com/eryajf/cloud_dns_exporter/pkg/provider"
 	"github.com/eryajf/cloud_dns_exporter/public/logger"
 
 	"github.com/eryajf/cloud_dns_exporter/public"
@@ -48,12 +50,28 @@ var rootCmd = &cobra.Command{
 		}
 		logger.InitLogger("debug")
 		public.InitSvc()
+		provider.RegisterPlugins(public.Config.Plugins)
+		checkFilters()
+		if err := notify.Init(public.Config.Notifications); err != nil {
+			logger.Fatal(fmt.Sprintf("init notifications failed: %v", err))
+		}
 		logger.Info("🚀 Start Cloud DNS Exporter, The Metrics Data Is Loading...")
 		export.InitCron()
 		RunServer()
 	},
 }
 
+// checkFilters 启动时校验所有账号的过滤规则，避免规则有误的账号在每次刷新时才失败
+func checkFilters() {
+	for cloudProvider, accounts := range public.Config.CloudProviders {
+		for _, account := range accounts.Accounts {
+			if _, err := provider.NewFilter(account); err != nil {
+				logger.Fatal(fmt.Sprintf("[ %s_%s ] invalid filter: %v", cloudProvider, account["name"], err))
+			}
+		}
+	}
+}
+
 func RunServer() {
 	metrics := export.NewMetrics("")
 	registory := prometheus.NewRegistry()
@@ -65,6 +83,9 @@ func RunServer() {
 			<body>
 			<h1>Cloud DNS Exporter</h1>
 			<p><a href='/metrics'>Metrics</a></p>
+			<p><a href='/probe?target=github.com&module=tls'>Probe github.com</a></p>
+			<p><a href='/api/changes'>Record Changes</a></p>
+			<p><a href='/api/v1/openapi.yaml'>API</a></p>
 			<p><a href='https://github.com/eryajf/cloud_dns_exporter'>Source Repo</a></p>
 			<p><a href='https://github.com/eryajf'>Create By Eryajf</a></p>
 			</body>
@@ -74,6 +95,9 @@ func RunServer() {
 		}
 	})
 	http.Handle("/metrics", promhttp.HandlerFor(registory, promhttp.HandlerOpts{Registry: registory}))
+	http.HandleFunc("/probe", export.ProbeHandler)
+	http.HandleFunc("/api/changes", export.ChangesHandler)
+	http.Handle("/api/v1/", export.APIHandler())
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "21798"
@@ -0,0 +1,414 @@
+package export
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/pkg/provider"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+	// maxPage 页码上限，保证 (page-1)*pageSize 在 32 位平台上也不会溢出
+	maxPage = 1000000
+)
+
+// AccountStatus 账号的数据获取状态
+type AccountStatus struct {
+	CloudProvider    string  `json:"cloud_provider"`
+	CloudName        string  `json:"cloud_name"`
+	DomainCount      int     `json:"domain_count"`
+	RecordCount      int     `json:"record_count"`
+	CertCount        int     `json:"cert_count"`
+	DomainsUpdatedAt string  `json:"domains_updated_at"` // 域名列表最近一次成功获取的时间，RFC3339
+	RecordsUpdatedAt string  `json:"records_updated_at"` // 记录列表最近一次成功获取的时间，RFC3339
+	DataAgeSeconds   float64 `json:"data_age_seconds"`   // 两者中较早的一个距今的秒数，未获取过时为 0
+	Stale            bool    `json:"stale"`
+	Withdrawn        bool    `json:"withdrawn"` // 超过最大过时时长，指标与接口均不再输出该账号的数据
+	LastError        string  `json:"last_error"`
+	LastErrorAt      string  `json:"last_error_at"`
+}
+
+// Page 分页返回的列表
+type Page struct {
+	Total    int         `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	Items    interface{} `json:"items"`
+}
+
+// refreshErrors 各账号最近一次获取数据失败的信息，获取成功后清除
+var refreshErrors = struct {
+	sync.Mutex
+	m map[[2]string][2]string
+}{m: make(map[[2]string][2]string)}
+
+func setRefreshError(cloudProvider, cloudName string, err error) {
+	refreshErrors.Lock()
+	defer refreshErrors.Unlock()
+	key := [2]string{cloudProvider, cloudName}
+	if err == nil {
+		delete(refreshErrors.m, key)
+		return
+	}
+	refreshErrors.m[key] = [2]string{err.Error(), time.Now().Format(time.RFC3339)}
+}
+
+func getRefreshError(cloudProvider, cloudName string) (msg, at string) {
+	refreshErrors.Lock()
+	defer refreshErrors.Unlock()
+	v := refreshErrors.m[[2]string{cloudProvider, cloudName}]
+	return v[0], v[1]
+}
+
+// getCached 从缓存中读取 JSON 序列化的列表，与 Metrics.Collect 使用相同的缓存
+func getCached(store public.Store, key string, v interface{}) error {
+	value, err := store.Get(key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(value, v)
+}
+
+// accountWithdrawn 数据超过最大过时时长时与指标一致，不再输出该账号的数据
+func accountWithdrawn(cloudProvider, cloudName, data string) bool {
+	updatedAt, ok := dataUpdatedAt(cloudProvider, cloudName, data)
+	return ok && time.Since(updatedAt) > public.Config.Staleness.MaxStalenessDuration()
+}
+
+// eachAccount 遍历配置中的账号
+func eachAccount(fn func(cloudProvider, cloudName string)) {
+	for cloudProvider, accounts := range public.Config.CloudProviders {
+		for _, account := range accounts.Accounts {
+			fn(cloudProvider, account["name"])
+		}
+	}
+}
+
+func listDomains() []provider.Domain {
+	domains := []provider.Domain{}
+	eachAccount(func(cloudProvider, cloudName string) {
+		if accountWithdrawn(cloudProvider, cloudName, public.DomainList) {
+			return
+		}
+		var v []provider.Domain
+		key := public.DomainList + "_" + cloudProvider + "_" + cloudName
+		if err := getCached(public.Cache, key, &v); err != nil {
+			logger.Debug(fmt.Sprintf("[ %s ] get domain list failed: %v", key, err))
+		}
+		domains = append(domains, v...)
+	})
+	return domains
+}
+
+func listRecords() []provider.Record {
+	records := []provider.Record{}
+	eachAccount(func(cloudProvider, cloudName string) {
+		if accountWithdrawn(cloudProvider, cloudName, public.RecordList) {
+			return
+		}
+		var v []provider.Record
+		key := public.RecordList + "_" + cloudProvider + "_" + cloudName
+		if err := getCached(public.Cache, key, &v); err != nil {
+			logger.Debug(fmt.Sprintf("[ %s ] get record list failed: %v", key, err))
+		}
+		records = append(records, v...)
+	})
+	return records
+}
+
+// listCerts 返回各账号及自定义记录的证书信息，记录列表已撤回的账号与指标一致不再返回其证书
+func listCerts() []provider.RecordCert {
+	certs := cachedCerts(public.RecordCertInfo + "_" + public.CustomRecords)
+	eachAccount(func(cloudProvider, cloudName string) {
+		if accountWithdrawn(cloudProvider, cloudName, public.RecordList) {
+			return
+		}
+		certs = append(certs, cachedCerts(public.RecordCertInfo+"_"+cloudProvider+"_"+cloudName)...)
+	})
+	return certs
+}
+
+// cachedCerts 读取缓存中的证书信息，跳过未获取到证书的记录
+func cachedCerts(key string) []provider.RecordCert {
+	certs := []provider.RecordCert{}
+	var v []provider.RecordCert
+	if err := getCached(public.CertCache, key, &v); err != nil {
+		logger.Debug(fmt.Sprintf("[ %s ] get record cert info failed: %v", key, err))
+	}
+	for _, c := range v {
+		if c.FullRecord != "" {
+			certs = append(certs, c)
+		}
+	}
+	return certs
+}
+
+func listAccounts() []AccountStatus {
+	staleAfter := public.Config.Staleness.StaleAfterDuration()
+	accounts := []AccountStatus{}
+	eachAccount(func(cloudProvider, cloudName string) {
+		a := AccountStatus{
+			CloudProvider: cloudProvider,
+			CloudName:     cloudName,
+			CertCount:     len(cachedCerts(public.RecordCertInfo + "_" + cloudProvider + "_" + cloudName)),
+		}
+		var domains []provider.Domain
+		var records []provider.Record
+		_ = getCached(public.Cache, public.DomainList+"_"+cloudProvider+"_"+cloudName, &domains)
+		_ = getCached(public.Cache, public.RecordList+"_"+cloudProvider+"_"+cloudName, &records)
+		a.DomainCount, a.RecordCount = len(domains), len(records)
+		var oldest time.Time
+		for data, field := range map[string]*string{public.DomainList: &a.DomainsUpdatedAt, public.RecordList: &a.RecordsUpdatedAt} {
+			updatedAt, ok := dataUpdatedAt(cloudProvider, cloudName, data)
+			if !ok {
+				continue
+			}
+			*field = updatedAt.Format(time.RFC3339)
+			if oldest.IsZero() || updatedAt.Before(oldest) {
+				oldest = updatedAt
+			}
+		}
+		if !oldest.IsZero() {
+			age := time.Since(oldest)
+			a.DataAgeSeconds = age.Truncate(time.Second).Seconds()
+			a.Stale = age > staleAfter
+			a.Withdrawn = age > public.Config.Staleness.MaxStalenessDuration()
+		}
+		a.LastError, a.LastErrorAt = getRefreshError(cloudProvider, cloudName)
+		accounts = append(accounts, a)
+	})
+	return accounts
+}
+
+// listQuery 列表接口的查询参数
+type listQuery struct {
+	filters  map[string]string // JSON 字段名 -> 需要完全匹配的值，不区分大小写
+	contains map[string]string // JSON 字段名 -> 需要包含的子串，不区分大小写
+	sort     string
+	desc     bool
+	page     int
+	pageSize int
+}
+
+// parseListQuery 解析分页、排序参数及 exact、contains 中允许的筛选参数
+// contains 的键为查询参数名，值为对应的 JSON 字段名
+func parseListQuery(r *http.Request, fields map[string]bool, exact []string, contains map[string]string) (*listQuery, error) {
+	query := r.URL.Query()
+	q := &listQuery{
+		filters:  make(map[string]string),
+		contains: make(map[string]string),
+		page:     1,
+		pageSize: defaultPageSize,
+	}
+	for _, key := range exact {
+		if v := query.Get(key); v != "" {
+			q.filters[key] = v
+		}
+	}
+	for param, field := range contains {
+		if v := query.Get(param); v != "" {
+			q.contains[field] = v
+		}
+	}
+	if v := query.Get("sort"); v != "" {
+		q.sort = strings.TrimPrefix(v, "-")
+		q.desc = strings.HasPrefix(v, "-")
+		if !fields[q.sort] {
+			return nil, fmt.Errorf("invalid sort field %q", q.sort)
+		}
+	}
+	if v := query.Get("order"); v != "" {
+		switch v {
+		case "asc":
+		case "desc":
+			q.desc = true
+		default:
+			return nil, fmt.Errorf("invalid order %q, want asc or desc", v)
+		}
+	}
+	for key, dst := range map[string]*int{"page": &q.page, "page_size": &q.pageSize} {
+		if v := query.Get(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid %s %q", key, v)
+			}
+			*dst = n
+		}
+	}
+	if q.page > maxPage {
+		return nil, fmt.Errorf("invalid page %d, must not exceed %d", q.page, maxPage)
+	}
+	if q.pageSize > maxPageSize {
+		q.pageSize = maxPageSize
+	}
+	return q, nil
+}
+
+// jsonFields 返回结构体的 JSON 字段名，用于校验排序字段
+func jsonFields(v interface{}) map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// apply 对列表依次筛选、排序、分页，各字段以 JSON 序列化后的值比较
+func (q *listQuery) apply(items interface{}) (*Page, error) {
+	value, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(value, &rows); err != nil {
+		return nil, err
+	}
+	matched := []map[string]interface{}{}
+	for _, row := range rows {
+		if q.match(row) {
+			matched = append(matched, row)
+		}
+	}
+	if q.sort != "" {
+		sort.SliceStable(matched, func(i, j int) bool {
+			if q.desc {
+				return lessValue(matched[j][q.sort], matched[i][q.sort])
+			}
+			return lessValue(matched[i][q.sort], matched[j][q.sort])
+		})
+	}
+	start := (q.page - 1) * q.pageSize
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + q.pageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return &Page{Total: len(matched), Page: q.page, PageSize: q.pageSize, Items: matched[start:end]}, nil
+}
+
+func (q *listQuery) match(row map[string]interface{}) bool {
+	for field, want := range q.filters {
+		if !strings.EqualFold(fmt.Sprint(row[field]), want) {
+			return false
+		}
+	}
+	for field, sub := range q.contains {
+		if !strings.Contains(strings.ToLower(fmt.Sprint(row[field])), strings.ToLower(sub)) {
+			return false
+		}
+	}
+	return true
+}
+
+// lessValue 数值按大小比较，TTL 等以字符串保存的数字同样按数值比较，其他按字符串比较
+func lessValue(a, b interface{}) bool {
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA && okB {
+		return fa < fb
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// serveList 按查询参数返回分页后的列表
+func serveList(w http.ResponseWriter, r *http.Request, items interface{}, fields map[string]bool, exact []string, contains map[string]string) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	q, err := parseListQuery(r, fields, exact, contains)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := q.apply(items)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("Write Response Error: ", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// APIHandler 只读的 JSON 接口，数据来自定时任务写入的缓存
+//
+//	/api/v1/accounts      账号状态
+//	/api/v1/domains       域名
+//	/api/v1/records       解析记录
+//	/api/v1/certs         证书
+//	/api/v1/openapi.yaml  OpenAPI 文档
+func APIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/accounts", func(w http.ResponseWriter, r *http.Request) {
+		serveList(w, r, listAccounts(), jsonFields(AccountStatus{}),
+			[]string{"cloud_provider", "cloud_name", "stale"}, nil)
+	})
+	mux.HandleFunc("/api/v1/domains", func(w http.ResponseWriter, r *http.Request) {
+		serveList(w, r, listDomains(), jsonFields(provider.Domain{}),
+			[]string{"cloud_provider", "cloud_name", "domain_name", "domain_status"},
+			map[string]string{"q": "domain_name"})
+	})
+	mux.HandleFunc("/api/v1/records", func(w http.ResponseWriter, r *http.Request) {
+		serveList(w, r, listRecords(), jsonFields(provider.Record{}),
+			[]string{"cloud_provider", "cloud_name", "domain_name", "record_type", "record_name", "record_status", "full_record"},
+			map[string]string{"value": "record_value", "q": "full_record"})
+	})
+	mux.HandleFunc("/api/v1/certs", func(w http.ResponseWriter, r *http.Request) {
+		serveList(w, r, listCerts(), jsonFields(provider.RecordCert{}),
+			[]string{"cloud_provider", "cloud_name", "domain_name", "full_record", "cert_matched"},
+			map[string]string{"q": "full_record"})
+	})
+	mux.HandleFunc("/api/v1/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		if _, err := w.Write(openAPISpec); err != nil {
+			logger.Error("Write Response Error: ", err)
+		}
+	})
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "not found")
+	})
+	return mux
+}
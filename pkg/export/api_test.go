@@ -0,0 +1,103 @@
+package export
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/pkg/provider"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"gopkg.in/yaml.v2"
+)
+
+func TestServeListPagination(t *testing.T) {
+	var records []provider.Record
+	for i := 0; i < 5; i++ {
+		records = append(records, provider.Record{RecordID: strconv.Itoa(i), RecordTTL: strconv.Itoa(600 - i*100)})
+	}
+	serve := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/records?"+query, nil)
+		serveList(w, r, records, jsonFields(provider.Record{}), nil, nil)
+		return w
+	}
+
+	for _, query := range []string{
+		"page=0",
+		"page=-1",
+		"page=1000001",
+		"page=9223372036854775807",
+		"page=9223372036854775808",
+		"page_size=abc",
+		"sort=unknown",
+		"order=up",
+	} {
+		if w := serve(query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want 400", query, w.Code)
+		}
+	}
+
+	w := serve("sort=record_ttl&page=2&page_size=2")
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body)
+	}
+	var page struct {
+		Total int               `json:"total"`
+		Items []provider.Record `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	// record_ttl 按数值升序：100(4)、200(3)、300(2)、400(1)、500(0)
+	if page.Total != 5 || len(page.Items) != 2 || page.Items[0].RecordID != "2" || page.Items[1].RecordID != "1" {
+		t.Errorf("unexpected page %+v", page)
+	}
+
+	if w := serve("page=1000000&page_size=1000"); w.Code != http.StatusOK {
+		t.Errorf("got status %d for the last allowed page", w.Code)
+	}
+}
+
+func TestListCertsSkipsWithdrawnAccounts(t *testing.T) {
+	logger.InitLogger("info")
+	config := &public.Configuration{}
+	if err := yaml.Unmarshal([]byte(`
+staleness:
+  max_staleness: 1h
+cloud_providers:
+  tencent:
+    accounts:
+      - name: fresh
+      - name: withdrawn
+`), config); err != nil {
+		t.Fatal(err)
+	}
+	public.Config = config
+	t.Cleanup(func() { public.Config = nil })
+	for _, store := range []*public.Store{&public.Cache, &public.CertCache} {
+		s, err := public.NewStore(public.StorageConfig{}, "test", time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		*store = s
+	}
+
+	for name, updatedAt := range map[string]time.Time{"fresh": time.Now(), "withdrawn": time.Now().Add(-2 * time.Hour)} {
+		if err := public.Cache.Set(public.DataUpdatedAt+"_"+public.RecordList+"_tencent_"+name, []byte(strconv.FormatInt(updatedAt.Unix(), 10))); err != nil {
+			t.Fatal(err)
+		}
+		value, _ := json.Marshal([]provider.RecordCert{{CloudProvider: "tencent", CloudName: name, FullRecord: "www.example.com"}})
+		if err := public.CertCache.Set(public.RecordCertInfo+"_tencent_"+name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	certs := listCerts()
+	if len(certs) != 1 || certs[0].CloudName != "fresh" {
+		t.Errorf("got %+v, want only the certs of the fresh account", certs)
+	}
+}
@@ -3,7 +3,9 @@ package export
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/eryajf/cloud_dns_exporter/public/logger"
 	"github.com/weppos/publicsuffix-go/publicsuffix"
@@ -19,13 +21,25 @@ func InitCron() {
 	_, _ = c.AddFunc("*/30 * * * * *", func() {
 		loading()
 	})
-	loading()
 	_, _ = c.AddFunc("03 03 03 * * *", func() {
-		loadingCert()
+		loadingCert(false)
 		loadingCustomRecordCert()
 	})
-	loadingCert()
-	loadingCustomRecordCert()
+	boot := func() {
+		loading()
+		// 持久化存储中已有证书数据时不再在启动时重新检测，等待每日的定时任务刷新
+		persistent := public.Config.Storage.Persistent()
+		loadingCert(persistent)
+		if _, err := public.CertCache.Get(public.RecordCertInfo + "_" + public.CustomRecords); !persistent || err != nil {
+			loadingCustomRecordCert()
+		}
+	}
+	if public.Config.Storage.Persistent() {
+		// 持久化存储中保留了上次获取的数据，启动后立即提供服务，在后台刷新
+		go boot()
+	} else {
+		boot()
+	}
 
 	c.Start()
 }
@@ -43,13 +57,23 @@ func loading() {
 				dnsProvider, err := provider.Factory.Create(cloudProvider, account)
 				if err != nil {
 					logger.Error(fmt.Sprintf("[ %s ] create provider failed: %v", domainListCacheKey, err))
+					refreshFailed(cloudProvider, cloudName, "create provider", err)
+					return
+				}
+				filter, err := provider.NewFilter(account)
+				if err != nil {
+					logger.Error(fmt.Sprintf("[ %s ] create filter failed: %v", domainListCacheKey, err))
+					refreshFailed(cloudProvider, cloudName, "create filter", err)
 					return
 				}
 				domains, err := dnsProvider.ListDomains()
 				if err != nil {
 					logger.Error(fmt.Sprintf("[ %s ] list domains failed: %v", domainListCacheKey, err))
+					refreshFailed(cloudProvider, cloudName, "list domains", err)
 					return
 				}
+				domains = filter.FilterDomains(domains)
+				notifyDomainExpiry(domains)
 
 				mu.Lock()
 				value, err := json.Marshal(domains)
@@ -58,6 +82,8 @@ func loading() {
 				}
 				if err := public.Cache.Set(domainListCacheKey, value); err != nil {
 					logger.Error(fmt.Sprintf("[ %s ] cache domain list failed: %v", domainListCacheKey, err))
+				} else {
+					setDataUpdatedAt(public.DomainList, cloudProvider, cloudName)
 				}
 				mu.Unlock()
 
@@ -65,8 +91,11 @@ func loading() {
 				records, err := dnsProvider.ListRecords()
 				if err != nil {
 					logger.Error(fmt.Sprintf("[ %s ] list records failed: %v", recordListCacheKey, err))
+					refreshFailed(cloudProvider, cloudName, "list records", err)
 					return
 				}
+				records = filter.FilterRecords(records)
+				trackRecordChanges(cloudProvider, cloudName, domains, records)
 				mu.Lock()
 				value, err = json.Marshal(records)
 				if err != nil {
@@ -74,15 +103,33 @@ func loading() {
 				}
 				if err := public.Cache.Set(recordListCacheKey, value); err != nil {
 					logger.Error(fmt.Sprintf("[ %s ] cache record list failed: %v", recordListCacheKey, err))
+				} else {
+					setDataUpdatedAt(public.RecordList, cloudProvider, cloudName)
 				}
 				mu.Unlock()
+				setRefreshError(cloudProvider, cloudName, nil)
 			}(cloudProvider, cloudAccount["name"], cloudAccount)
 		}
 	}
 	wg.Wait()
 }
 
-func loadingCert() {
+// refreshFailed 记录账号数据获取失败的信息，并发送通知，stage 表示失败的步骤
+func refreshFailed(cloudProvider, cloudName, stage string, err error) {
+	setRefreshError(cloudProvider, cloudName, fmt.Errorf("%s failed: %w", stage, err))
+	notifyRefreshFailed(cloudProvider, cloudName, stage, err)
+}
+
+// setDataUpdatedAt 记录域名或记录列表最近一次成功获取的时间，用于计算数据是否过时
+func setDataUpdatedAt(data, cloudProvider, cloudName string) {
+	key := public.DataUpdatedAt + "_" + data + "_" + cloudProvider + "_" + cloudName
+	if err := public.Cache.Set(key, []byte(strconv.FormatInt(time.Now().Unix(), 10))); err != nil {
+		logger.Error(fmt.Sprintf("[ %s ] cache update time failed: %v", key, err))
+	}
+}
+
+// loadingCert 获取解析记录的证书信息，onlyMissing 为 true 时跳过缓存中已有证书数据的账号
+func loadingCert(onlyMissing bool) {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
@@ -91,6 +138,11 @@ func loadingCert() {
 			wg.Add(1)
 			go func(cloudProvider, cloudName string, account map[string]string) {
 				defer wg.Done()
+				if onlyMissing {
+					if _, err := public.CertCache.Get(public.RecordCertInfo + "_" + cloudProvider + "_" + cloudName); err == nil {
+						return
+					}
+				}
 				recordListCacheKey := public.RecordList + "_" + cloudProvider + "_" + cloudName
 				var records []provider.Record
 				rst2, err := public.Cache.Get(recordListCacheKey)
@@ -110,6 +162,7 @@ func loadingCert() {
 						FullRecord:    v.FullRecord,
 						RecordValue:   v.RecordValue,
 						RecordID:      v.RecordID,
+						CertSource:    certSource(v),
 					})
 				}
 				recordCerts, err := GetMultipleCertInfo(recordCertReq)
@@ -117,6 +170,7 @@ func loadingCert() {
 					logger.Error(fmt.Sprintf("[ %s ] get record cert info failed: %v", recordListCacheKey, err))
 					return
 				}
+				notifyCerts(recordCerts)
 
 				mu.Lock()
 				recordCertInfoCacheKey := public.RecordCertInfo + "_" + cloudProvider + "_" + cloudName
@@ -164,6 +218,7 @@ func loadingCustomRecordCert() {
 			FullRecord:    v.FullRecord,
 			RecordValue:   v.RecordValue,
 			RecordID:      v.RecordID,
+			CertSource:    certSource(v),
 		})
 	}
 	recordCerts, err := GetMultipleCertInfo(recordCertReq)
@@ -171,6 +226,7 @@ func loadingCustomRecordCert() {
 		logger.Error(fmt.Sprintf("[ custom ] get record cert info failed: %v", err))
 		return
 	}
+	notifyCerts(recordCerts)
 	recordCertInfoCacheKey := public.RecordCertInfo + "_" + public.CustomRecords
 	value, err := json.Marshal(recordCerts)
 	if err != nil {
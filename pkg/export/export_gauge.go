@@ -3,7 +3,12 @@ package export
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/golang-module/carbon/v2"
 
 	"github.com/eryajf/cloud_dns_exporter/public/logger"
 
@@ -15,11 +20,20 @@ import (
 // 指标结构体
 type Metrics struct {
 	metrics map[string]*prometheus.Desc
+	builtin map[string][]string // 各指标的内置标签
+	output  map[string][]string // 各指标经过标签配置处理后实际输出的标签
+	rules   *labelRules
+	seen    map[string]struct{}
 	mutex   sync.Mutex
 }
 
-// newGlobalMetric 创建指标描述符
-func newGlobalMetric(namespace string, metricName string, docString string, labels []string) *prometheus.Desc {
+// newGlobalMetric 创建指标描述符，标签按配置文件中的 labels 处理
+func (c *Metrics) newGlobalMetric(namespace string, metricName string, docString string, labels []string) *prometheus.Desc {
+	c.builtin[metricName] = labels
+	if c.rules != nil {
+		labels = c.rules.outputLabels(labels)
+	}
+	c.output[metricName] = labels
 	if namespace == "" {
 		return prometheus.NewDesc(metricName, docString, labels, nil)
 	} else {
@@ -29,61 +43,234 @@ func newGlobalMetric(namespace string, metricName string, docString string, labe
 
 // NewMetrics 初始化指标信息，即Metrics结构体
 func NewMetrics(namespace string) *Metrics {
-	return &Metrics{
-		metrics: map[string]*prometheus.Desc{
-			public.DomainList: newGlobalMetric(namespace,
-				public.DomainList,
-				"Cloud Domain List",
-				[]string{
-					"cloud_provider",
-					"cloud_name",
-					"domain_id",
-					"domain_name",
-					"domain_remark",
-					"domain_status",
-					"created_date",
-					"expiry_date",
-				}),
-			public.RecordList: newGlobalMetric(namespace,
-				public.RecordList,
-				"Cloud Doamin Record List",
-				[]string{
-					"cloud_provider",
-					"cloud_name",
-					"domain_name",
-					"record_id",
-					"record_type",
-					"record_name",
-					"record_value",
-					"record_ttl",
-					"record_weight",
-					"record_status",
-					"record_remark",
-					"update_time",
-					"full_record",
-				}),
-			public.RecordCertInfo: newGlobalMetric(namespace,
-				public.RecordCertInfo,
-				"Cloud Doamin Record Cert Info",
-				[]string{
-					"cloud_provider",
-					"cloud_name",
-					"domain_name",
-					"record_id",
-					"full_record",
-					"subject_common_name",
-					"subject_organization",
-					"subject_organizational_unit",
-					"issuer_common_name",
-					"issuer_organization",
-					"issuer_organizational_unit",
-					"created_date",
-					"expiry_date",
-					"cert_matched",
-					"error_msg",
-				}),
-		},
+	rules, err := newLabelRules(public.Config.Labels)
+	if err != nil {
+		logger.Fatal("init metric labels failed: ", err)
+	}
+	c := &Metrics{
+		builtin: make(map[string][]string),
+		output:  make(map[string][]string),
+		rules:   rules,
+	}
+	c.metrics = map[string]*prometheus.Desc{
+		public.DomainList: c.newGlobalMetric(namespace,
+			public.DomainList,
+			"Cloud Domain List",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"domain_id",
+				"domain_name",
+				"domain_remark",
+				"domain_status",
+				"created_date",
+				"expiry_date",
+				"auto_renew",
+				"locked",
+			}),
+		public.DomainSOASerial: c.newGlobalMetric(namespace,
+			public.DomainSOASerial,
+			"Cloud Domain SOA Serial",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"domain_id",
+				"domain_name",
+			}),
+		public.DomainLabelInfo: c.newGlobalMetric(namespace,
+			public.DomainLabelInfo,
+			"Cloud Domain Provider Specific Labels",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"domain_id",
+				"domain_name",
+				"label",
+				"value",
+			}),
+		public.RecordLabelInfo: c.newGlobalMetric(namespace,
+			public.RecordLabelInfo,
+			"Cloud Doamin Record Provider Specific Labels",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"domain_name",
+				"record_id",
+				"label",
+				"value",
+			}),
+		public.RecordList: c.newGlobalMetric(namespace,
+			public.RecordList,
+			"Cloud Doamin Record List",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"domain_name",
+				"record_id",
+				"record_type",
+				"record_name",
+				"record_value",
+				"record_ttl",
+				"record_weight",
+				"record_line",
+				"record_line_id",
+				"record_preference",
+				"record_group",
+				"record_status",
+				"record_remark",
+				"update_time",
+				"full_record",
+			}),
+		public.RecordCertInfo: c.newGlobalMetric(namespace,
+			public.RecordCertInfo,
+			"Cloud Doamin Record Cert Info",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"domain_name",
+				"record_id",
+				"full_record",
+				"subject_common_name",
+				"subject_organization",
+				"subject_organizational_unit",
+				"issuer_common_name",
+				"issuer_organization",
+				"issuer_organizational_unit",
+				"created_date",
+				"expiry_date",
+				"cert_matched",
+				"cert_source",
+				"error_msg",
+			}),
+		public.DomainInfo: c.newGlobalMetric(namespace,
+			public.DomainInfo,
+			"Cloud Domain Info",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"domain_id",
+				"domain_name",
+			}),
+		public.DomainExpiryTimestampSeconds: c.newGlobalMetric(namespace,
+			public.DomainExpiryTimestampSeconds,
+			"Cloud Domain Expiry Time In Unix Seconds",
+			domainKeyLabels),
+		public.DomainCreatedTimestampSeconds: c.newGlobalMetric(namespace,
+			public.DomainCreatedTimestampSeconds,
+			"Cloud Domain Created Time In Unix Seconds",
+			domainKeyLabels),
+		public.RecordInfo: c.newGlobalMetric(namespace,
+			public.RecordInfo,
+			"Cloud Domain Record Info",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"domain_name",
+				"record_id",
+				"record_type",
+				"record_name",
+				"full_record",
+			}),
+		public.RecordTTLSeconds: c.newGlobalMetric(namespace,
+			public.RecordTTLSeconds,
+			"Cloud Domain Record TTL In Seconds",
+			recordKeyLabels),
+		public.RecordUpdatedTimestampSeconds: c.newGlobalMetric(namespace,
+			public.RecordUpdatedTimestampSeconds,
+			"Cloud Domain Record Updated Time In Unix Seconds",
+			recordKeyLabels),
+		public.ZoneRecordCount: c.newGlobalMetric(namespace,
+			public.ZoneRecordCount,
+			"Cloud Domain Record Count By Type",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"domain_name",
+				"record_type",
+			}),
+		public.CertInfo: c.newGlobalMetric(namespace,
+			public.CertInfo,
+			"Cloud Domain Record Cert Info",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"domain_name",
+				"record_id",
+				"full_record",
+				"subject_common_name",
+				"issuer_common_name",
+				"cert_source",
+			}),
+		public.CertNotBeforeTimestampSeconds: c.newGlobalMetric(namespace,
+			public.CertNotBeforeTimestampSeconds,
+			"Cloud Domain Record Cert Not Before Time In Unix Seconds",
+			recordKeyLabels),
+		public.CertNotAfterTimestampSeconds: c.newGlobalMetric(namespace,
+			public.CertNotAfterTimestampSeconds,
+			"Cloud Domain Record Cert Not After Time In Unix Seconds",
+			recordKeyLabels),
+		public.CertMatched: c.newGlobalMetric(namespace,
+			public.CertMatched,
+			"Whether The Cloud Domain Record Cert Matches The Domain",
+			recordKeyLabels),
+		public.RecordChangesTotal: c.newGlobalMetric(namespace,
+			public.RecordChangesTotal,
+			"Cloud Domain Record Changes Since Start",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"domain_name",
+				"change_type",
+			}),
+		public.DataAgeSeconds: c.newGlobalMetric(namespace,
+			public.DataAgeSeconds,
+			"Age Of The Cached Domain Or Record List In Seconds",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"data",
+			}),
+		public.DataStale: c.newGlobalMetric(namespace,
+			public.DataStale,
+			"Whether The Cached Domain Or Record List Is Stale",
+			[]string{
+				"cloud_provider",
+				"cloud_name",
+				"data",
+			}),
+	}
+	return c
+}
+
+var (
+	// recordKeyLabels v2 指标中用于关联 record_info、cert_info 的标签
+	recordKeyLabels = []string{"cloud_provider", "cloud_name", "domain_name", "record_id"}
+	// domainKeyLabels v2 指标中用于关联 domain_info 的标签
+	domainKeyLabels = []string{"cloud_provider", "cloud_name", "domain_id", "domain_name"}
+)
+
+// metricsSchema 返回配置的指标模型，未配置时为 legacy，其他取值在加载配置时已拒绝
+func metricsSchema() (legacy, v2 bool) {
+	switch public.Config.MetricsSchema {
+	case public.MetricsSchemaV2:
+		return false, true
+	case public.MetricsSchemaBoth:
+		return true, true
+	default:
+		return true, false
+	}
+}
+
+// timestampSeconds 将日期字符串解析为 Unix 时间戳，无法解析时返回 false
+func timestampSeconds(date string) (float64, bool) {
+	if date == "" {
+		return 0, false
+	}
+	t := carbon.Parse(date)
+	if t.Error != nil || t.IsZero() {
+		return 0, false
 	}
+	return float64(t.Timestamp()), true
 }
 
 // Describe 传递结构体中的指标描述符到channel
@@ -98,12 +285,18 @@ func (c *Metrics) Collect(ch chan<- prometheus.Metric) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
+	legacy, v2 := metricsSchema()
+	c.seen = make(map[string]struct{})
+
 	for cloudProvider, accounts := range public.Config.CloudProviders {
 		for _, cloudAccount := range accounts.Accounts {
 			cloudName := cloudAccount["name"]
 			// get domain list from cache
 			domainListCacheKey := public.DomainList + "_" + cloudProvider + "_" + cloudName
 			var domains []provider.Domain
+			if !c.dataStaleness(ch, cloudProvider, cloudName, public.DomainList) {
+				continue
+			}
 			domainListCacheValue, err := public.Cache.Get(domainListCacheKey)
 			if err != nil {
 				logger.Error(fmt.Sprintf("[ %s ] get domain list failed: %v", domainListCacheKey, err))
@@ -115,12 +308,25 @@ func (c *Metrics) Collect(ch chan<- prometheus.Metric) {
 				continue
 			}
 			for _, v := range domains {
-				ch <- prometheus.MustNewConstMetric(
-					c.metrics[public.DomainList], prometheus.GaugeValue, float64(v.DaysUntilExpiry), v.CloudProvider, v.CloudName, v.DomainID, v.DomainName, v.DomainRemark, v.DomainStatus, v.CreatedDate, v.ExpiryDate)
+				if legacy {
+					c.send(ch, public.DomainList, float64(v.DaysUntilExpiry), v.CloudProvider, v.CloudName, v.DomainID, v.DomainName, v.DomainRemark, v.DomainStatus, v.CreatedDate, v.ExpiryDate, v.AutoRenew, v.Locked)
+				}
+				if v2 {
+					c.collectDomainV2(ch, v)
+				}
+				if v.SOASerial > 0 {
+					c.send(ch, public.DomainSOASerial, float64(v.SOASerial), v.CloudProvider, v.CloudName, v.DomainID, v.DomainName)
+				}
+				for label, value := range v.Labels {
+					c.send(ch, public.DomainLabelInfo, 1, v.CloudProvider, v.CloudName, v.DomainID, v.DomainName, label, value)
+				}
 			}
 			// get record list from cache
 			recordListCacheKey := public.RecordList + "_" + cloudProvider + "_" + cloudName
 			var records []provider.Record
+			if !c.dataStaleness(ch, cloudProvider, cloudName, public.RecordList) {
+				continue
+			}
 			recordListCacheValue, err := public.Cache.Get(recordListCacheKey)
 			if err != nil {
 				logger.Error(fmt.Sprintf("[ %s ] get record list failed: %v", domainListCacheKey, err))
@@ -131,12 +337,24 @@ func (c *Metrics) Collect(ch chan<- prometheus.Metric) {
 				logger.Error(fmt.Sprintf("[ %s ] json.Unmarshal error: %v", domainListCacheKey, err))
 				continue
 			}
+			recordCounts := make(map[[2]string]int)
 			for _, v := range records {
 				if v.RecordName == "@" && v.RecordType == "NS" { // Special Record, Skip it.
 					continue
 				}
-				ch <- prometheus.MustNewConstMetric(
-					c.metrics[public.RecordList], prometheus.GaugeValue, 1, v.CloudProvider, v.CloudName, v.DomainName, v.RecordID, v.RecordType, v.RecordName, v.RecordValue, v.RecordTTL, v.RecordWeight, v.RecordStatus, v.RecordRemark, v.UpdateTime, v.FullRecord)
+				if legacy {
+					c.send(ch, public.RecordList, 1, v.CloudProvider, v.CloudName, v.DomainName, v.RecordID, v.RecordType, v.RecordName, v.RecordValue, v.RecordTTL, v.RecordWeight, v.RecordLine, v.RecordLineID, v.RecordPreference, v.RecordGroup, v.RecordStatus, v.RecordRemark, v.UpdateTime, v.FullRecord)
+				}
+				if v2 {
+					c.collectRecordV2(ch, v)
+					recordCounts[[2]string{v.DomainName, v.RecordType}]++
+				}
+				for label, value := range v.Labels {
+					c.send(ch, public.RecordLabelInfo, 1, v.CloudProvider, v.CloudName, v.DomainName, v.RecordID, label, value)
+				}
+			}
+			for key, count := range recordCounts {
+				c.send(ch, public.ZoneRecordCount, float64(count), cloudProvider, cloudName, key[0], key[1])
 			}
 			// get record cert info list from cache
 			recordCertInfoCacheKey := public.RecordCertInfo + "_" + cloudProvider + "_" + cloudName
@@ -155,11 +373,15 @@ func (c *Metrics) Collect(ch chan<- prometheus.Metric) {
 				if v.RecordID == "" {
 					continue
 				}
-				ch <- prometheus.MustNewConstMetric(c.metrics[public.RecordCertInfo], prometheus.GaugeValue, float64(v.DaysUntilExpiry), v.CloudProvider, v.CloudName, v.DomainName, v.RecordID, v.FullRecord, v.SubjectCommonName, v.SubjectOrganization, v.SubjectOrganizationalUnit, v.IssuerCommonName, v.IssuerOrganization, v.IssuerOrganizationalUnit, v.CreatedDate, v.ExpiryDate, fmt.Sprintf("%t", v.CertMatched), v.ErrorMsg)
+				c.collectRecordCert(ch, v, legacy, v2)
 			}
 		}
 	}
 
+	for key, count := range journal.changeCounts() {
+		c.emit(ch, public.RecordChangesTotal, prometheus.CounterValue, float64(count), key[0], key[1], key[2], key[3])
+	}
+
 	// get custom record cert info list from cache
 	recordCertInfoCacheKey := public.RecordCertInfo + "_" + public.CustomRecords
 	var recordCerts []provider.RecordCert
@@ -172,6 +394,109 @@ func (c *Metrics) Collect(ch chan<- prometheus.Metric) {
 		logger.Error(fmt.Sprintf("[ %s ] json.Unmarshal error: %v", recordCertInfoCacheKey, err))
 	}
 	for _, v := range recordCerts {
-		ch <- prometheus.MustNewConstMetric(c.metrics[public.RecordCertInfo], prometheus.GaugeValue, float64(v.DaysUntilExpiry), v.CloudProvider, v.CloudName, v.DomainName, v.RecordID, v.FullRecord, v.SubjectCommonName, v.SubjectOrganization, v.SubjectOrganizationalUnit, v.IssuerCommonName, v.IssuerOrganization, v.IssuerOrganizationalUnit, v.CreatedDate, v.ExpiryDate, fmt.Sprintf("%t", v.CertMatched), v.ErrorMsg)
+		c.collectRecordCert(ch, v, legacy, v2)
+	}
+}
+
+// collectDomainV2 输出 v2 模型的域名指标，日期以数值形式输出，避免日期变化时产生新的时间序列
+func (c *Metrics) collectDomainV2(ch chan<- prometheus.Metric, v provider.Domain) {
+	c.send(ch, public.DomainInfo, 1, v.CloudProvider, v.CloudName, v.DomainID, v.DomainName)
+	if ts, ok := timestampSeconds(v.ExpiryDate); ok {
+		c.send(ch, public.DomainExpiryTimestampSeconds, ts, v.CloudProvider, v.CloudName, v.DomainID, v.DomainName)
+	}
+	if ts, ok := timestampSeconds(v.CreatedDate); ok {
+		c.send(ch, public.DomainCreatedTimestampSeconds, ts, v.CloudProvider, v.CloudName, v.DomainID, v.DomainName)
+	}
+}
+
+// collectRecordV2 输出 v2 模型的解析记录指标，记录值、备注等易变字段不再作为标签
+func (c *Metrics) collectRecordV2(ch chan<- prometheus.Metric, v provider.Record) {
+	c.send(ch, public.RecordInfo, 1, v.CloudProvider, v.CloudName, v.DomainName, v.RecordID, v.RecordType, v.RecordName, v.FullRecord)
+	if ttl, err := strconv.Atoi(v.RecordTTL); err == nil {
+		c.send(ch, public.RecordTTLSeconds, float64(ttl), v.CloudProvider, v.CloudName, v.DomainName, v.RecordID)
+	}
+	if ts, ok := timestampSeconds(v.UpdateTime); ok {
+		c.send(ch, public.RecordUpdatedTimestampSeconds, ts, v.CloudProvider, v.CloudName, v.DomainName, v.RecordID)
+	}
+}
+
+// collectRecordCert 按指标模型输出解析记录的证书指标
+func (c *Metrics) collectRecordCert(ch chan<- prometheus.Metric, v provider.RecordCert, legacy, v2 bool) {
+	if legacy {
+		c.send(ch, public.RecordCertInfo, float64(v.DaysUntilExpiry), v.CloudProvider, v.CloudName, v.DomainName, v.RecordID, v.FullRecord, v.SubjectCommonName, v.SubjectOrganization, v.SubjectOrganizationalUnit, v.IssuerCommonName, v.IssuerOrganization, v.IssuerOrganizationalUnit, v.CreatedDate, v.ExpiryDate, fmt.Sprintf("%t", v.CertMatched), v.CertSource, v.ErrorMsg)
+	}
+	if !v2 {
+		return
+	}
+	c.send(ch, public.CertInfo, 1, v.CloudProvider, v.CloudName, v.DomainName, v.RecordID, v.FullRecord, v.SubjectCommonName, v.IssuerCommonName, v.CertSource)
+	// 获取证书失败时没有有效期，不输出时间指标
+	if v.NotAfter > 0 {
+		c.send(ch, public.CertNotBeforeTimestampSeconds, float64(v.NotBefore), v.CloudProvider, v.CloudName, v.DomainName, v.RecordID)
+		c.send(ch, public.CertNotAfterTimestampSeconds, float64(v.NotAfter), v.CloudProvider, v.CloudName, v.DomainName, v.RecordID)
+		c.send(ch, public.CertMatched, boolToFloat(v.CertMatched), v.CloudProvider, v.CloudName, v.DomainName, v.RecordID)
+	}
+}
+
+// dataStaleness 输出账号缓存数据的时长及是否已过时，超过最大过时时长时返回 false，该账号的指标不再输出
+// 数据来源于最近一次成功的获取，提供商接口故障期间继续输出上次的数据，避免时间序列消失
+// 过时状态单独输出为 data_stale，不作为域名及记录指标的标签，避免账号过时时这些指标产生新的时间序列
+func (c *Metrics) dataStaleness(ch chan<- prometheus.Metric, cloudProvider, cloudName, data string) bool {
+	updatedAt, ok := dataUpdatedAt(cloudProvider, cloudName, data)
+	if !ok {
+		// 尚无更新时间的数据(如旧版本写入的持久化缓存)视为未过时
+		c.send(ch, public.DataStale, 0, cloudProvider, cloudName, data)
+		return true
+	}
+	age := time.Since(updatedAt)
+	if age > public.Config.Staleness.MaxStalenessDuration() {
+		logger.Warning(fmt.Sprintf("[ %s_%s_%s ] data is %s old, exceeds max staleness, withdrawn", data, cloudProvider, cloudName, age.Truncate(time.Second)))
+		return false
+	}
+	c.send(ch, public.DataAgeSeconds, age.Seconds(), cloudProvider, cloudName, data)
+	c.send(ch, public.DataStale, boolToFloat(age > public.Config.Staleness.StaleAfterDuration()), cloudProvider, cloudName, data)
+	return true
+}
+
+// dataUpdatedAt 返回账号的域名或记录列表最近一次成功获取的时间
+func dataUpdatedAt(cloudProvider, cloudName, data string) (time.Time, bool) {
+	value, err := public.Cache.Get(public.DataUpdatedAt + "_" + data + "_" + cloudProvider + "_" + cloudName)
+	if err != nil {
+		return time.Time{}, false
+	}
+	updatedAt, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(updatedAt, 0), true
+}
+
+// send 输出一个 Gauge 类型的样本
+func (c *Metrics) send(ch chan<- prometheus.Metric, name string, value float64, labelValues ...string) {
+	c.emit(ch, name, prometheus.GaugeValue, value, labelValues...)
+}
+
+// emit 输出一个样本，配置了标签规则时先按规则处理标签
+// 标签完全相同的样本只输出第一个，重复的样本会使整次抓取失败，通常由标签规则或提供商返回的重复数据导致
+func (c *Metrics) emit(ch chan<- prometheus.Metric, name string, valueType prometheus.ValueType, value float64, labelValues ...string) {
+	if c.rules != nil {
+		var ok bool
+		labelValues, ok = c.rules.apply(c.builtin[name], labelValues, c.output[name])
+		if !ok {
+			return
+		}
+	}
+	key := name + "\xff" + strings.Join(labelValues, "\xff")
+	if _, exists := c.seen[key]; exists {
+		logger.Warning(fmt.Sprintf("[ %s ] duplicate series skipped: %v", name, labelValues))
+		return
+	}
+	c.seen[key] = struct{}{}
+	ch <- prometheus.MustNewConstMetric(c.metrics[name], valueType, value, labelValues...)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
 	}
+	return 0
 }
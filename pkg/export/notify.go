@@ -0,0 +1,111 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/eryajf/cloud_dns_exporter/pkg/notify"
+	"github.com/eryajf/cloud_dns_exporter/pkg/provider"
+)
+
+// notifyRecordChanges 将记录变更转换为通知事件
+func notifyRecordChanges(changes []RecordChange) {
+	if !notify.Enabled() {
+		return
+	}
+	var events []notify.Event
+	for _, c := range changes {
+		e := notify.Event{
+			Time:          c.Time,
+			CloudProvider: c.CloudProvider,
+			CloudName:     c.CloudName,
+			DomainName:    c.DomainName,
+		}
+		switch c.ChangeType {
+		case ChangeAdded:
+			e.Type = notify.EventRecordAdded
+			e.Message = fmt.Sprintf("record added: %s", describeRecord(c.After))
+		case ChangeRemoved:
+			e.Type = notify.EventRecordRemoved
+			e.Message = fmt.Sprintf("record removed: %s", describeRecord(c.Before))
+		case ChangeModified:
+			e.Type = notify.EventRecordModified
+			e.Message = fmt.Sprintf("record modified: %s -> %s", describeRecord(c.Before), describeRecord(c.After))
+		}
+		// 变更事件本身不重复，按变更时间区分
+		e.Key = e.Type + "|" + c.CloudProvider + "|" + c.CloudName + "|" + c.DomainName + "|" + c.RecordID + "|" + c.Time
+		events = append(events, e)
+	}
+	notify.Publish(events...)
+}
+
+func describeRecord(r *provider.Record) string {
+	return fmt.Sprintf("%s %s %s (ttl %s, status %s)", r.FullRecord, r.RecordType, r.RecordValue, r.RecordTTL, r.RecordStatus)
+}
+
+// notifyRefreshFailed 账号数据获取失败时通知，stage 表示失败的步骤
+func notifyRefreshFailed(cloudProvider, cloudName, stage string, err error) {
+	notify.Publish(notify.Event{
+		Type:          notify.EventRefreshFailed,
+		CloudProvider: cloudProvider,
+		CloudName:     cloudName,
+		Message:       fmt.Sprintf("%s failed: %v", stage, err),
+		Key:           notify.EventRefreshFailed + "|" + cloudProvider + "|" + cloudName + "|" + stage,
+	})
+}
+
+// notifyDomainExpiry 域名剩余天数不超过配置的天数时通知，未获取到到期时间的域名跳过
+func notifyDomainExpiry(domains []provider.Domain) {
+	if !notify.Enabled() {
+		return
+	}
+	days := int64(notify.DomainExpiryDays())
+	var events []notify.Event
+	for _, d := range domains {
+		if d.ExpiryDate == "" || d.DaysUntilExpiry > days {
+			continue
+		}
+		events = append(events, notify.Event{
+			Type:          notify.EventDomainExpiring,
+			CloudProvider: d.CloudProvider,
+			CloudName:     d.CloudName,
+			DomainName:    d.DomainName,
+			Message:       fmt.Sprintf("domain %s expires in %d days (%s)", d.DomainName, d.DaysUntilExpiry, d.ExpiryDate),
+			Key:           notify.EventDomainExpiring + "|" + d.CloudProvider + "|" + d.CloudName + "|" + d.DomainName,
+		})
+	}
+	notify.Publish(events...)
+}
+
+// notifyCerts 证书即将过期或与域名不匹配时通知
+func notifyCerts(certs []provider.RecordCert) {
+	if !notify.Enabled() {
+		return
+	}
+	days := notify.CertExpiryDays()
+	var events []notify.Event
+	for _, c := range certs {
+		key := c.CloudProvider + "|" + c.CloudName + "|" + c.FullRecord
+		// 获取证书失败时 CertMatched 同样为 false，通过 NotAfter 排除
+		if !c.CertMatched && c.NotAfter > 0 {
+			events = append(events, notify.Event{
+				Type:          notify.EventCertMismatch,
+				CloudProvider: c.CloudProvider,
+				CloudName:     c.CloudName,
+				DomainName:    c.DomainName,
+				Message:       fmt.Sprintf("cert of %s does not match, subject %s", c.FullRecord, c.SubjectCommonName),
+				Key:           notify.EventCertMismatch + "|" + key,
+			})
+		}
+		if c.NotAfter > 0 && c.DaysUntilExpiry <= days {
+			events = append(events, notify.Event{
+				Type:          notify.EventCertExpiring,
+				CloudProvider: c.CloudProvider,
+				CloudName:     c.CloudName,
+				DomainName:    c.DomainName,
+				Message:       fmt.Sprintf("cert of %s expires in %d days (%s)", c.FullRecord, c.DaysUntilExpiry, c.ExpiryDate),
+				Key:           notify.EventCertExpiring + "|" + key,
+			})
+		}
+	}
+	notify.Publish(events...)
+}
@@ -0,0 +1,185 @@
+package export
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/pkg/provider"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/weppos/publicsuffix-go/publicsuffix"
+)
+
+// defaultProbeModule 未指定 module 参数时使用的模块，未在配置文件中定义时按 443 端口检测证书
+const defaultProbeModule = "tls"
+
+// ProbeHandler 按 blackbox_exporter 的方式对单个目标即时检测，如 /probe?target=smtp.example.com:25&module=tls_smtp
+func ProbeHandler(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "Target parameter is missing", http.StatusBadRequest)
+		return
+	}
+	moduleName := r.URL.Query().Get("module")
+	if moduleName == "" {
+		moduleName = defaultProbeModule
+	}
+	module, ok := public.Config.ProbeModules[moduleName]
+	if !ok && moduleName != defaultProbeModule {
+		http.Error(w, fmt.Sprintf("Unknown module %q", moduleName), http.StatusBadRequest)
+		return
+	}
+	timeout := 10 * time.Second
+	if module.Timeout != "" {
+		d, err := time.ParseDuration(module.Timeout)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid timeout %q of module %q", module.Timeout, moduleName), http.StatusBadRequest)
+			return
+		}
+		timeout = d
+	}
+
+	registry := prometheus.NewRegistry()
+	probeSuccess := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "probe_success",
+		Help: "Whether The Probe Succeeded",
+	})
+	probeDuration := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "probe_duration_seconds",
+		Help: "Duration Of The Probe In Seconds",
+	})
+	registry.MustRegister(probeSuccess, probeDuration)
+
+	start := time.Now()
+	var err error
+	switch module.Prober {
+	case "", "tls":
+		err = probeTLS(target, module, timeout, registry)
+	case "dns":
+		err = probeDNS(target, module, timeout, registry)
+	default:
+		err = fmt.Errorf("unsupported prober %q", module.Prober)
+	}
+	probeDuration.Set(time.Since(start).Seconds())
+	if err != nil {
+		logger.Warning(fmt.Sprintf("[ probe ] %s of module %s failed: %v", target, moduleName, err))
+	} else {
+		probeSuccess.Set(1)
+	}
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// probeTLS 检测目标的证书，证书信息的提取与定时任务中的记录证书一致
+func probeTLS(target string, module public.ProbeModule, timeout time.Duration, registry *prometheus.Registry) error {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		host, port = target, module.Port
+		if port == "" {
+			port = "443"
+		}
+	}
+	serverName := module.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+	cert, err := getPeerCert(net.JoinHostPort(host, port), serverName, module.StartTLS, timeout)
+	if err != nil {
+		return err
+	}
+	domainName, err := publicsuffix.Domain(serverName)
+	if err != nil {
+		domainName = serverName
+	}
+	certInfo := newRecordCert(provider.GetRecordCertReq{
+		DomainName:  domainName,
+		FullRecord:  serverName,
+		RecordValue: host,
+	}, cert)
+
+	info := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: public.CertInfo,
+		Help: "Probed Cert Info",
+	}, []string{
+		"subject_common_name",
+		"subject_organization",
+		"issuer_common_name",
+		"issuer_organization",
+	})
+	notBefore := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: public.CertNotBeforeTimestampSeconds,
+		Help: "Probed Cert Not Before Time In Unix Seconds",
+	})
+	notAfter := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: public.CertNotAfterTimestampSeconds,
+		Help: "Probed Cert Not After Time In Unix Seconds",
+	})
+	matched := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: public.CertMatched,
+		Help: "Whether The Probed Cert Matches The Domain",
+	})
+	registry.MustRegister(info, notBefore, notAfter, matched)
+	info.WithLabelValues(certInfo.SubjectCommonName, certInfo.SubjectOrganization, certInfo.IssuerCommonName, certInfo.IssuerOrganization).Set(1)
+	notBefore.Set(float64(certInfo.NotBefore))
+	notAfter.Set(float64(certInfo.NotAfter))
+	matched.Set(boolToFloat(certInfo.CertMatched))
+	return nil
+}
+
+// probeDNS 向解析服务器查询目标的记录，响应码为 NOERROR 时视为成功
+func probeDNS(target string, module public.ProbeModule, timeout time.Duration, registry *prometheus.Registry) error {
+	host := target
+	if h, _, err := net.SplitHostPort(target); err == nil {
+		host = h
+	}
+	queryType := strings.ToUpper(module.QueryType)
+	if queryType == "" {
+		queryType = "A"
+	}
+	qtype, ok := dns.StringToType[queryType]
+	if !ok {
+		return fmt.Errorf("unsupported query_type %q", module.QueryType)
+	}
+	resolver := module.Resolver
+	if resolver == "" {
+		conf, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+		if err != nil || len(conf.Servers) == 0 {
+			return fmt.Errorf("no resolver configured and /etc/resolv.conf unavailable: %v", err)
+		}
+		resolver = net.JoinHostPort(conf.Servers[0], conf.Port)
+	} else if _, _, err := net.SplitHostPort(resolver); err != nil {
+		resolver = net.JoinHostPort(resolver, "53")
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(host), qtype)
+	client := &dns.Client{Timeout: timeout}
+	rsp, _, err := client.Exchange(msg, resolver)
+	if err != nil {
+		return err
+	}
+
+	answerCount := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "probe_dns_answer_count",
+		Help: "Number Of Records In The Answer Section",
+	})
+	records := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "probe_dns_record_info",
+		Help: "Records In The Answer Section",
+	}, []string{"record_type", "record_value"})
+	registry.MustRegister(answerCount, records)
+	answerCount.Set(float64(len(rsp.Answer)))
+	for _, rr := range rsp.Answer {
+		value := strings.TrimPrefix(rr.String(), rr.Header().String())
+		records.WithLabelValues(dns.TypeToString[rr.Header().Rrtype], value).Set(1)
+	}
+	if rsp.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("rcode %s", dns.RcodeToString[rsp.Rcode])
+	}
+	return nil
+}
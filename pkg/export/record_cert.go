@@ -3,8 +3,10 @@ package export
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
+	"net/smtp"
 	"strings"
 	"sync"
 	"time"
@@ -67,30 +69,64 @@ func GetMultipleCertInfo(records []provider.GetRecordCertReq) ([]provider.Record
 
 // GetCertInfo 获取证书信息
 func GetCertInfo(record provider.GetRecordCertReq) (certInfo provider.RecordCert, err error) {
+	cert, err := getPeerCert(record.RecordValue+":443", record.FullRecord, "", 3*time.Second)
+	if err != nil {
+		return certInfo, err
+	}
+	return newRecordCert(record, cert), nil
+}
+
+// getPeerCert 连接 addr 完成 TLS 握手并返回对端证书，starttls 为 smtp 时先以明文建立 SMTP 会话再升级
+func getPeerCert(addr, serverName, starttls string, timeout time.Duration) (*x509.Certificate, error) {
 	config := &tls.Config{
 		InsecureSkipVerify: true,
-		ServerName:         record.FullRecord,
+		ServerName:         serverName,
 	}
-	d := net.Dialer{
-		Timeout: time.Second * 3,
-	}
-	conn, err := tls.DialWithDialer(&d, "tcp", record.RecordValue+":443", config)
+	conn, err := net.DialTimeout("tcp", addr, timeout)
 	if err != nil {
-		return certInfo, err
+		return nil, err
 	}
 	defer conn.Close()
-	certs := conn.ConnectionState().PeerCertificates
-	if len(certs) == 0 {
-		return certInfo, fmt.Errorf("未找到证书")
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	var state tls.ConnectionState
+	switch starttls {
+	case "":
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.Handshake(); err != nil {
+			return nil, err
+		}
+		state = tlsConn.ConnectionState()
+	case "smtp":
+		c, err := smtp.NewClient(conn, serverName)
+		if err != nil {
+			return nil, err
+		}
+		defer c.Close()
+		if err := c.StartTLS(config); err != nil {
+			return nil, err
+		}
+		state, _ = c.TLSConnectionState()
+		_ = c.Quit()
+	default:
+		return nil, fmt.Errorf("unsupported starttls %q", starttls)
+	}
+	if len(state.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("未找到证书")
 	}
+	return state.PeerCertificates[0], nil
+}
 
+// newRecordCert 从证书中提取记录的证书信息
+func newRecordCert(record provider.GetRecordCertReq, cert *x509.Certificate) (certInfo provider.RecordCert) {
 	certInfo.CloudProvider = record.CloudProvider
 	certInfo.CloudName = record.CloudName
 	certInfo.DomainName = record.DomainName
 	certInfo.FullRecord = record.FullRecord
 	certInfo.RecordID = record.RecordID
+	certInfo.CertSource = record.CertSource
 
-	cert := certs[0]
 	certInfo.SubjectCommonName = cert.Subject.CommonName
 	if strings.Contains(certInfo.SubjectCommonName, record.DomainName) {
 		certInfo.CertMatched = true
@@ -115,10 +151,12 @@ func GetCertInfo(record provider.GetRecordCertReq) (certInfo provider.RecordCert
 	// 从证书中提取日期信息
 	certInfo.CreatedDate = cert.NotBefore.Format(time.DateOnly)
 	certInfo.ExpiryDate = cert.NotAfter.Format(time.DateOnly)
+	certInfo.NotBefore = cert.NotBefore.Unix()
+	certInfo.NotAfter = cert.NotAfter.Unix()
 	// 计算距离到期日期还有多少天
 	daysUntilExpiry := int(time.Until(cert.NotAfter).Hours() / 24)
 	certInfo.DaysUntilExpiry = daysUntilExpiry
-	return certInfo, nil
+	return certInfo
 }
 
 // getNewRecord 判断域名解析记录是否符合可获取ssl证书信息的条件
@@ -135,6 +173,11 @@ func getNewRecord(records []provider.Record) (newRecords []provider.Record) {
 			if strings.Contains(rec.FullRecord, "*") {
 				rec.FullRecord = strings.ReplaceAll(rec.FullRecord, "*", "a")
 			}
+			// cloudflare 等代理记录的证书由边缘节点提供，源站地址往往无法直接访问，改为连接记录本身，
+			// 检测到的是边缘节点而非源站的证书，通过 cert_source 标签区分
+			if rec.Labels["proxied"] == "true" {
+				rec.RecordValue = rec.FullRecord
+			}
 			if (rec.RecordType == "A" || rec.RecordType == "CNAME") &&
 				rec.RecordStatus == "enable" && isPortOpen(rec.RecordValue) {
 				recordChan <- rec
@@ -151,6 +194,17 @@ func getNewRecord(records []provider.Record) (newRecords []provider.Record) {
 	return
 }
 
+// certSource 证书来源，优先使用 provider 标记的来源，否则代理记录为 edge，其他为 origin
+func certSource(rec provider.Record) string {
+	if source := rec.Labels["cert_source"]; source != "" {
+		return source
+	}
+	if rec.Labels["proxied"] == "true" {
+		return "edge"
+	}
+	return "origin"
+}
+
 // isPortOpen 检查给定域名的443端口是否通
 func isPortOpen(domain string) bool {
 	timeout := 1 * time.Second
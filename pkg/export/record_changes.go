@@ -0,0 +1,325 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/pkg/provider"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// changeMemorySize 未配置变更日志文件时在内存中保留的最近变更条数
+const changeMemorySize = 1000
+
+// RecordChange 解析记录的一次变更，新增时 Before 为空，删除时 After 为空
+type RecordChange struct {
+	Time          string           `json:"time"`
+	ChangeType    string           `json:"change_type"`
+	CloudProvider string           `json:"cloud_provider"`
+	CloudName     string           `json:"cloud_name"`
+	DomainName    string           `json:"domain_name"`
+	RecordID      string           `json:"record_id"`
+	Before        *provider.Record `json:"before,omitempty"`
+	After         *provider.Record `json:"after,omitempty"`
+}
+
+// changeJournal 追加写入的变更日志，配置了文件路径时以 JSONL 格式写入文件，否则仅在内存中保留最近的变更
+type changeJournal struct {
+	mu     sync.Mutex
+	recent []RecordChange
+	counts map[[4]string]int // cloud_provider、cloud_name、domain_name、change_type 对应的变更次数
+}
+
+var journal = &changeJournal{counts: make(map[[4]string]int)}
+
+// trackRecordChanges 将新获取的记录与上一次的快照对比，变更写入日志，并保存新的快照
+// 域名仍在域名列表中但本次没有任何记录时，视为该域名的记录获取失败，沿用上次的记录，避免记为全部删除
+func trackRecordChanges(cloudProvider, cloudName string, domains []provider.Domain, records []provider.Record) {
+	snapshotKey := public.RecordSnapshot + "_" + cloudProvider + "_" + cloudName
+	var previous []provider.Record
+	value, err := public.Cache.Get(snapshotKey)
+	if err == nil {
+		if err := json.Unmarshal(value, &previous); err != nil {
+			logger.Error(fmt.Sprintf("[ %s ] json.Unmarshal error: %v", snapshotKey, err))
+			previous = nil
+		}
+	}
+	snapshot := records
+	if err == nil {
+		activeDomains := make(map[string]bool, len(domains))
+		for _, d := range domains {
+			activeDomains[d.DomainName] = true
+		}
+		currentDomains := make(map[string]bool)
+		for _, r := range records {
+			currentDomains[r.DomainName] = true
+		}
+		var kept []provider.Record
+		for _, r := range previous {
+			if activeDomains[r.DomainName] && !currentDomains[r.DomainName] {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) > 0 {
+			snapshot = append(append([]provider.Record(nil), records...), kept...)
+		}
+		changes := diffRecords(previous, snapshot)
+		journal.append(changes)
+		notifyRecordChanges(changes)
+	}
+	value, err = json.Marshal(snapshot)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ %s ] marshal record snapshot failed: %v", snapshotKey, err))
+		return
+	}
+	if err := public.Cache.Set(snapshotKey, value); err != nil {
+		logger.Error(fmt.Sprintf("[ %s ] cache record snapshot failed: %v", snapshotKey, err))
+	}
+}
+
+// diffRecords 以记录ID对比两次快照
+func diffRecords(previous, current []provider.Record) (changes []RecordChange) {
+	now := time.Now().Format(time.RFC3339)
+	before := make(map[string]provider.Record, len(previous))
+	for _, r := range previous {
+		before[r.DomainName+"|"+r.RecordID] = r
+	}
+	after := make(map[string]bool, len(current))
+	for _, r := range current {
+		key := r.DomainName + "|" + r.RecordID
+		after[key] = true
+		old, ok := before[key]
+		switch {
+		case !ok:
+			changes = append(changes, newRecordChange(now, ChangeAdded, nil, &r))
+		case recordChanged(old, r):
+			changes = append(changes, newRecordChange(now, ChangeModified, &old, &r))
+		}
+	}
+	for _, r := range previous {
+		if !after[r.DomainName+"|"+r.RecordID] {
+			changes = append(changes, newRecordChange(now, ChangeRemoved, &r, nil))
+		}
+	}
+	return changes
+}
+
+func newRecordChange(now, changeType string, before, after *provider.Record) RecordChange {
+	r := after
+	if r == nil {
+		r = before
+	}
+	return RecordChange{
+		Time:          now,
+		ChangeType:    changeType,
+		CloudProvider: r.CloudProvider,
+		CloudName:     r.CloudName,
+		DomainName:    r.DomainName,
+		RecordID:      r.RecordID,
+		Before:        before,
+		After:         after,
+	}
+}
+
+// recordChanged 只比较记录本身的配置，更新时间及提供商特有的标签不视为变更
+func recordChanged(a, b provider.Record) bool {
+	return a.RecordType != b.RecordType ||
+		a.RecordName != b.RecordName ||
+		a.RecordValue != b.RecordValue ||
+		a.RecordTTL != b.RecordTTL ||
+		a.RecordWeight != b.RecordWeight ||
+		a.RecordLine != b.RecordLine ||
+		a.RecordPreference != b.RecordPreference ||
+		a.RecordStatus != b.RecordStatus ||
+		a.RecordRemark != b.RecordRemark
+}
+
+func (j *changeJournal) append(changes []RecordChange) {
+	if len(changes) == 0 {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, c := range changes {
+		j.counts[[4]string{c.CloudProvider, c.CloudName, c.DomainName, c.ChangeType}]++
+	}
+	path := public.Config.ChangeJournal.Path
+	if path == "" {
+		j.recent = append(j.recent, changes...)
+		if len(j.recent) > changeMemorySize {
+			j.recent = j.recent[len(j.recent)-changeMemorySize:]
+		}
+		return
+	}
+	if err := appendJSONLines(path, changes); err != nil {
+		logger.Error(fmt.Sprintf("[ %s ] write change journal failed: %v", path, err))
+	}
+}
+
+func appendJSONLines(path string, changes []RecordChange) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	encoder := json.NewEncoder(f)
+	for _, c := range changes {
+		if err := encoder.Encode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeCounts 返回自启动以来各账号、域名的变更次数
+func (j *changeJournal) changeCounts() map[[4]string]int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	counts := make(map[[4]string]int, len(j.counts))
+	for k, v := range j.counts {
+		counts[k] = v
+	}
+	return counts
+}
+
+// list 按条件筛选变更，按时间倒序返回最多 limit 条
+func (j *changeJournal) list(match func(RecordChange) bool, limit int) ([]RecordChange, error) {
+	rst := []RecordChange{}
+	if path := public.Config.ChangeJournal.Path; path != "" {
+		// 日志文件可能很大，不持有锁，从文件末尾向前读取，取到足够的条数即停止
+		err := scanJSONLinesReverse(path, func(c RecordChange) bool {
+			if match(c) {
+				rst = append(rst, c)
+			}
+			return len(rst) < limit
+		})
+		return rst, err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for i := len(j.recent) - 1; i >= 0 && len(rst) < limit; i-- {
+		if match(j.recent[i]) {
+			rst = append(rst, j.recent[i])
+		}
+	}
+	return rst, nil
+}
+
+// journalChunkSize 从日志文件末尾向前读取时每次读取的字节数
+const journalChunkSize = 64 * 1024
+
+// scanJSONLinesReverse 从文件末尾向前逐行读取变更，fn 返回 false 时停止
+// 只读取打开时已有的内容，正在追加的不完整的行解析失败后跳过
+func scanJSONLinesReverse(path string, fn func(RecordChange) bool) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	emit := func(line []byte) bool {
+		var c RecordChange
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &c) != nil {
+			return true
+		}
+		return fn(c)
+	}
+	var (
+		offset = info.Size()
+		rest   []byte // 已读取部分开头的行，可能不完整，与前一块拼接后再解析
+	)
+	for offset > 0 {
+		n := int64(journalChunkSize)
+		if offset < n {
+			n = offset
+		}
+		offset -= n
+		buf := make([]byte, n, n+int64(len(rest)))
+		if _, err := f.ReadAt(buf, offset); err != nil {
+			return err
+		}
+		lines := bytes.Split(append(buf, rest...), []byte("\n"))
+		rest = lines[0]
+		for i := len(lines) - 1; i > 0; i-- {
+			if !emit(lines[i]) {
+				return nil
+			}
+		}
+	}
+	emit(rest)
+	return nil
+}
+
+// ChangesHandler 查询记录变更，支持 cloud_provider、cloud_name、domain_name、change_type、since(RFC3339) 与 limit(默认 100) 参数
+func ChangesHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 100
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, fmt.Sprintf("Invalid limit %q", v), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	var since time.Time
+	if v := query.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid since %q, want RFC3339", v), http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+	match := func(c RecordChange) bool {
+		for key, value := range map[string]string{
+			"cloud_provider": c.CloudProvider,
+			"cloud_name":     c.CloudName,
+			"domain_name":    c.DomainName,
+			"change_type":    c.ChangeType,
+		} {
+			if v := query.Get(key); v != "" && v != value {
+				return false
+			}
+		}
+		if !since.IsZero() {
+			t, err := time.Parse(time.RFC3339, c.Time)
+			if err != nil || t.Before(since) {
+				return false
+			}
+		}
+		return true
+	}
+	changes, err := journal.list(match, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(changes); err != nil {
+		logger.Error("Write Response Error: ", err)
+	}
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/eryajf/cloud_dns_exporter/pkg/provider"
+	"github.com/eryajf/cloud_dns_exporter/public"
+)
+
+func TestChangeJournalListFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.jsonl")
+	public.Config = &public.Configuration{ChangeJournal: public.ChangeJournalConfig{Path: path}}
+	t.Cleanup(func() { public.Config = nil })
+
+	// 每条约 1KB，共跨越多个读取块
+	var changes []RecordChange
+	for i := 0; i < 300; i++ {
+		changes = append(changes, RecordChange{
+			ChangeType: ChangeAdded,
+			CloudName:  strconv.Itoa(i % 3),
+			RecordID:   strconv.Itoa(i),
+			After:      &provider.Record{RecordRemark: strings.Repeat("x", 1000)},
+		})
+	}
+	if err := appendJSONLines(path, changes); err != nil {
+		t.Fatal(err)
+	}
+	// 正在写入的不完整的行
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"change_type":"add`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	j := &changeJournal{counts: make(map[[4]string]int)}
+	got, err := j.list(func(c RecordChange) bool { return c.CloudName == "1" }, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 100 {
+		t.Fatalf("got %d changes, want 100", len(got))
+	}
+	for i, c := range got {
+		if want := strconv.Itoa(298 - 3*i); c.RecordID != want {
+			t.Fatalf("change %d has record_id %s, want %s", i, c.RecordID, want)
+		}
+	}
+
+	got, err = j.list(func(RecordChange) bool { return true }, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].RecordID != "299" || got[1].RecordID != "298" {
+		t.Errorf("unexpected latest changes %+v", got)
+	}
+
+	public.Config.ChangeJournal.Path = filepath.Join(t.TempDir(), "missing.jsonl")
+	if got, err := j.list(func(RecordChange) bool { return true }, 10); err != nil || len(got) != 0 {
+		t.Errorf("got %d changes, %v for a missing file", len(got), err)
+	}
+}
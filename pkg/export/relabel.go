@@ -0,0 +1,199 @@
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/prometheus/common/model"
+)
+
+// labelRules 由配置文件中的 labels 生成，决定每个指标最终输出的标签
+type labelRules struct {
+	include map[string]bool
+	exclude map[string]bool
+	static  []staticLabel
+	relabel []relabelRule
+	extra   []string // 静态标签与重写规则新增的标签名，追加在内置标签之后
+}
+
+type staticLabel struct {
+	cloudProvider string
+	cloudName     string
+	domainRegex   *regexp.Regexp
+	labels        map[string]string
+}
+
+type relabelRule struct {
+	sourceLabels []string
+	separator    string
+	regex        *regexp.Regexp
+	targetLabel  string
+	replacement  string
+	action       string
+}
+
+// newLabelRules 校验并编译标签配置，未配置任何规则时返回 nil
+func newLabelRules(cfg public.LabelConfig) (*labelRules, error) {
+	if len(cfg.Include) == 0 && len(cfg.Exclude) == 0 && len(cfg.Static) == 0 && len(cfg.Relabel) == 0 {
+		return nil, nil
+	}
+	r := &labelRules{
+		include: make(map[string]bool),
+		exclude: make(map[string]bool),
+	}
+	for _, name := range cfg.Include {
+		r.include[name] = true
+	}
+	for _, name := range cfg.Exclude {
+		r.exclude[name] = true
+	}
+	extra := make(map[string]bool)
+	for _, s := range cfg.Static {
+		sl := staticLabel{
+			cloudProvider: s.CloudProvider,
+			cloudName:     s.CloudName,
+			labels:        s.Labels,
+		}
+		if s.DomainRegex != "" {
+			re, err := regexp.Compile(s.DomainRegex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid static domain_regex %q: %w", s.DomainRegex, err)
+			}
+			sl.domainRegex = re
+		}
+		for name := range s.Labels {
+			if !model.LabelName(name).IsValid() {
+				return nil, fmt.Errorf("invalid static label name %q", name)
+			}
+			extra[name] = true
+		}
+		r.static = append(r.static, sl)
+	}
+	for _, c := range cfg.Relabel {
+		rule := relabelRule{
+			sourceLabels: c.SourceLabels,
+			separator:    c.Separator,
+			targetLabel:  c.TargetLabel,
+			replacement:  c.Replacement,
+			action:       strings.ToLower(c.Action),
+		}
+		if c.Separator == "" {
+			rule.separator = ";"
+		}
+		if c.Replacement == "" {
+			rule.replacement = "$1"
+		}
+		if rule.action == "" {
+			rule.action = "replace"
+		}
+		regex := c.Regex
+		if regex == "" {
+			regex = "(.*)"
+		}
+		re, err := regexp.Compile("^(?:" + regex + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid relabel regex %q: %w", c.Regex, err)
+		}
+		rule.regex = re
+		switch rule.action {
+		case "replace":
+			if !model.LabelName(c.TargetLabel).IsValid() {
+				return nil, fmt.Errorf("invalid relabel target_label %q", c.TargetLabel)
+			}
+			extra[c.TargetLabel] = true
+		case "keep", "drop":
+		default:
+			return nil, fmt.Errorf("unsupported relabel action %q", c.Action)
+		}
+		r.relabel = append(r.relabel, rule)
+	}
+	for name := range extra {
+		r.extra = append(r.extra, name)
+	}
+	sort.Strings(r.extra)
+	return r, nil
+}
+
+// outputLabels 返回指标最终输出的标签名：经过 include/exclude 过滤的内置标签加上新增的标签
+func (r *labelRules) outputLabels(builtin []string) []string {
+	var labels []string
+	isBuiltin := make(map[string]bool, len(builtin))
+	for _, name := range builtin {
+		isBuiltin[name] = true
+		if len(r.include) > 0 && !r.include[name] {
+			continue
+		}
+		if r.exclude[name] {
+			continue
+		}
+		labels = append(labels, name)
+	}
+	for _, name := range r.extra {
+		if !isBuiltin[name] {
+			labels = append(labels, name)
+		}
+	}
+	return labels
+}
+
+// apply 依次附加静态标签、执行重写规则，返回 output 中各标签的取值，规则要求丢弃时返回 false
+func (r *labelRules) apply(builtin, values, output []string) ([]string, bool) {
+	set := make(map[string]string, len(builtin)+len(r.extra))
+	for i, name := range builtin {
+		set[name] = values[i]
+	}
+	for _, s := range r.static {
+		if !s.match(set) {
+			continue
+		}
+		for name, value := range s.labels {
+			// 静态标签不覆盖内置标签
+			if _, exists := set[name]; !exists {
+				set[name] = value
+			}
+		}
+	}
+	for _, rule := range r.relabel {
+		source := make([]string, len(rule.sourceLabels))
+		for i, name := range rule.sourceLabels {
+			source[i] = set[name]
+		}
+		value := strings.Join(source, rule.separator)
+		matches := rule.regex.FindStringSubmatchIndex(value)
+		switch rule.action {
+		case "keep":
+			if matches == nil {
+				return nil, false
+			}
+		case "drop":
+			if matches != nil {
+				return nil, false
+			}
+		case "replace":
+			if matches != nil {
+				set[rule.targetLabel] = string(rule.regex.ExpandString(nil, rule.replacement, value, matches))
+			}
+		}
+	}
+	rst := make([]string, len(output))
+	for i, name := range output {
+		rst[i] = set[name]
+	}
+	return rst, true
+}
+
+func (s staticLabel) match(set map[string]string) bool {
+	if s.cloudProvider != "" && s.cloudProvider != set["cloud_provider"] {
+		return false
+	}
+	if s.cloudName != "" && s.cloudName != set["cloud_name"] {
+		return false
+	}
+	if s.domainRegex != nil && !s.domainRegex.MatchString(set["domain_name"]) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var testLabels = []string{"cloud_provider", "cloud_name", "domain_name", "record_id", "record_remark"}
+
+func TestNewLabelRules(t *testing.T) {
+	r, err := newLabelRules(public.LabelConfig{})
+	if err != nil || r != nil {
+		t.Errorf("got %v, %v without rules, want nil rules", r, err)
+	}
+	for _, cfg := range []public.LabelConfig{
+		{Static: []public.StaticLabel{{DomainRegex: "("}}},
+		{Static: []public.StaticLabel{{Labels: map[string]string{"bad-name": "x"}}}},
+		{Relabel: []public.RelabelConfig{{Regex: "("}}},
+		{Relabel: []public.RelabelConfig{{TargetLabel: ""}}},
+		{Relabel: []public.RelabelConfig{{Action: "hashmod"}}},
+	} {
+		if _, err := newLabelRules(cfg); err == nil {
+			t.Errorf("want error for %+v", cfg)
+		}
+	}
+}
+
+func TestLabelRulesOutputLabels(t *testing.T) {
+	r, err := newLabelRules(public.LabelConfig{
+		Exclude: []string{"record_remark"},
+		Static:  []public.StaticLabel{{Labels: map[string]string{"team": "ops"}}},
+		Relabel: []public.RelabelConfig{{SourceLabels: []string{"domain_name"}, TargetLabel: "env"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"cloud_provider", "cloud_name", "domain_name", "record_id", "env", "team"}
+	if got := r.outputLabels(testLabels); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	r, err = newLabelRules(public.LabelConfig{Include: []string{"cloud_name", "domain_name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.outputLabels(testLabels); !reflect.DeepEqual(got, []string{"cloud_name", "domain_name"}) {
+		t.Errorf("got %v with include", got)
+	}
+}
+
+func TestLabelRulesApply(t *testing.T) {
+	r, err := newLabelRules(public.LabelConfig{
+		Static: []public.StaticLabel{
+			{CloudProvider: "tencent", DomainRegex: `\.cn$`, Labels: map[string]string{"team": "cn"}},
+			// 静态标签不覆盖内置标签
+			{Labels: map[string]string{"cloud_name": "overridden"}},
+		},
+		Relabel: []public.RelabelConfig{
+			{SourceLabels: []string{"domain_name"}, Regex: `(\w+)\.(.*)`, TargetLabel: "env", Replacement: "$2"},
+			{SourceLabels: []string{"cloud_name", "record_id"}, Regex: "test;.*", Action: "drop"},
+			{SourceLabels: []string{"record_remark"}, Regex: "(ok)?", Action: "keep"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := r.outputLabels(testLabels)
+
+	got, ok := r.apply(testLabels, []string{"tencent", "prod", "example.cn", "1", ""}, output)
+	want := []string{"tencent", "prod", "example.cn", "1", "", "cn", "cn"}
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, %v, want %v", got, ok, want)
+	}
+
+	got, ok = r.apply(testLabels, []string{"aliyun", "prod", "example.com", "2", ""}, output)
+	want = []string{"aliyun", "prod", "example.com", "2", "", "com", ""}
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, %v, want %v", got, ok, want)
+	}
+
+	if _, ok := r.apply(testLabels, []string{"aliyun", "test", "example.com", "3", ""}, output); ok {
+		t.Error("want dropped by drop rule")
+	}
+	if _, ok := r.apply(testLabels, []string{"aliyun", "prod", "example.com", "4", "remark"}, output); ok {
+		t.Error("want dropped by keep rule")
+	}
+}
+
+func TestEmitSkipsDuplicateSeries(t *testing.T) {
+	logger.InitLogger("info")
+	for _, cfg := range []public.LabelConfig{{}, {Exclude: []string{"record_id"}}} {
+		rules, err := newLabelRules(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := &Metrics{
+			builtin: make(map[string][]string),
+			output:  make(map[string][]string),
+			rules:   rules,
+			seen:    make(map[string]struct{}),
+		}
+		c.metrics = map[string]*prometheus.Desc{
+			public.RecordInfo: c.newGlobalMetric("", public.RecordInfo, "test", []string{"domain_name", "record_id"}),
+		}
+		ch := make(chan prometheus.Metric, 4)
+		c.send(ch, public.RecordInfo, 1, "example.com", "1")
+		c.send(ch, public.RecordInfo, 1, "example.com", "1")
+		c.send(ch, public.RecordInfo, 1, "example.com", "2")
+		close(ch)
+		want := 2
+		if rules != nil {
+			// 去掉 record_id 后两条记录的标签相同
+			want = 1
+		}
+		if got := len(ch); got != want {
+			t.Errorf("got %d series with %+v, want %d", got, cfg, want)
+		}
+	}
+}
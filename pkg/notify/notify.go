@@ -0,0 +1,271 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+// 事件类型
+const (
+	EventRecordAdded    = "record_added"
+	EventRecordRemoved  = "record_removed"
+	EventRecordModified = "record_modified"
+	EventDomainExpiring = "domain_expiring"
+	EventCertExpiring   = "cert_expiring"
+	EventCertMismatch   = "cert_mismatch"
+	EventRefreshFailed  = "refresh_failed"
+)
+
+// Event 一条通知事件
+type Event struct {
+	Type          string `json:"type"`
+	Time          string `json:"time"`
+	CloudProvider string `json:"cloud_provider"`
+	CloudName     string `json:"cloud_name"`
+	DomainName    string `json:"domain_name,omitempty"`
+	Message       string `json:"message"`
+	Key           string `json:"-"` // 去重使用的键，为空时使用 Type 与 Message
+}
+
+// defaultTemplate 未配置模板时使用的消息模板
+const defaultTemplate = `Cloud DNS Exporter
+{{range .Events}}[{{.Type}}] {{.CloudProvider}}/{{.CloudName}} {{.Message}}
+{{end}}`
+
+// maxPending 每个通知渠道最多保留的待发送事件数，渠道持续发送失败时丢弃最早的事件
+const maxPending = 1000
+
+// Notifier 收集事件，按节流窗口合并后发送到各通知渠道
+type Notifier struct {
+	config public.NotificationConfig
+	sinks  []*sink
+	mu     sync.Mutex // 保护各渠道的待发送事件及去重记录
+}
+
+var defaultNotifier *Notifier
+
+// Init 按配置初始化通知，未配置通知渠道时不做任何事
+func Init(config public.NotificationConfig) error {
+	if len(config.Sinks) == 0 {
+		return nil
+	}
+	n := &Notifier{config: config}
+	for i, c := range config.Sinks {
+		s, err := newSink(c)
+		if err != nil {
+			return fmt.Errorf("notification sink %d (%s): %w", i, c.Name, err)
+		}
+		n.sinks = append(n.sinks, s)
+	}
+	defaultNotifier = n
+	go n.run()
+	return nil
+}
+
+// Enabled 是否配置了通知渠道
+func Enabled() bool {
+	return defaultNotifier != nil
+}
+
+// DomainExpiryDays 返回域名到期通知的天数
+func DomainExpiryDays() int {
+	if defaultNotifier == nil || defaultNotifier.config.DomainExpiryDays <= 0 {
+		return 30
+	}
+	return defaultNotifier.config.DomainExpiryDays
+}
+
+// CertExpiryDays 返回证书到期通知的天数
+func CertExpiryDays() int {
+	if defaultNotifier == nil || defaultNotifier.config.CertExpiryDays <= 0 {
+		return 15
+	}
+	return defaultNotifier.config.CertExpiryDays
+}
+
+// Publish 提交事件，已在等待发送或去重窗口内已成功发送过的事件会被忽略
+func Publish(events ...Event) {
+	n := defaultNotifier
+	if n == nil || len(events) == 0 {
+		return
+	}
+	now := time.Now()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, e := range events {
+		if e.Key == "" {
+			e.Key = e.Type + "|" + e.CloudProvider + "|" + e.CloudName + "|" + e.Message
+		}
+		if e.Time == "" {
+			e.Time = now.Format(time.RFC3339)
+		}
+		for _, s := range n.sinks {
+			s.queue(e, now, n.config.DedupWindowDuration())
+		}
+	}
+}
+
+func (n *Notifier) run() {
+	ticker := time.NewTicker(n.config.ThrottleWindowDuration())
+	defer ticker.Stop()
+	for range ticker.C {
+		n.flush()
+	}
+}
+
+// flush 发送节流窗口内累积的事件，发送成功后才记入去重记录，失败的事件在下一个窗口重新发送
+func (n *Notifier) flush() {
+	window := n.config.DedupWindowDuration()
+	for _, s := range n.sinks {
+		n.mu.Lock()
+		events := s.pending
+		s.pending = nil
+		for key, t := range s.sent {
+			if time.Since(t) >= window {
+				delete(s.sent, key)
+			}
+		}
+		n.mu.Unlock()
+		if len(events) == 0 {
+			continue
+		}
+		err := s.send(events)
+		n.mu.Lock()
+		if err != nil {
+			logger.Error(fmt.Sprintf("[ notify ] send %d events to %s failed, retry in next window: %v", len(events), s.name(), err))
+			s.requeue(events)
+		} else {
+			now := time.Now()
+			for _, e := range events {
+				delete(s.queued, e.Key)
+				s.sent[e.Key] = now
+			}
+		}
+		n.mu.Unlock()
+	}
+}
+
+type sink struct {
+	config   public.NotificationSink
+	template *template.Template
+	events   map[string]bool
+	pending  []Event
+	queued   map[string]bool      // 等待发送的事件
+	sent     map[string]time.Time // 去重窗口内已成功发送的事件
+}
+
+func newSink(c public.NotificationSink) (*sink, error) {
+	switch c.Type {
+	case "webhook", "dingtalk", "feishu", "wecom", "slack":
+		if c.URL == "" {
+			return nil, fmt.Errorf("url is required")
+		}
+	case "email":
+		if c.SMTPHost == "" || c.From == "" || len(c.To) == 0 {
+			return nil, fmt.Errorf("smtp_host, from and to are required")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %q", c.Type)
+	}
+	text := c.Template
+	if text == "" {
+		text = defaultTemplate
+	}
+	tpl, err := template.New(c.Name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template failed: %w", err)
+	}
+	s := &sink{
+		config:   c,
+		template: tpl,
+		events:   make(map[string]bool),
+		queued:   make(map[string]bool),
+		sent:     make(map[string]time.Time),
+	}
+	for _, e := range c.Events {
+		s.events[e] = true
+	}
+	return s, nil
+}
+
+func (s *sink) name() string {
+	if s.config.Name != "" {
+		return s.config.Name
+	}
+	return s.config.Type
+}
+
+// queue 渠道订阅了该类型的事件，且事件不在等待发送、去重窗口内也未成功发送过时加入待发送事件
+func (s *sink) queue(e Event, now time.Time, window time.Duration) {
+	if len(s.events) > 0 && !s.events[e.Type] {
+		return
+	}
+	if s.queued[e.Key] {
+		return
+	}
+	if last, ok := s.sent[e.Key]; ok && now.Sub(last) < window {
+		return
+	}
+	s.queued[e.Key] = true
+	s.pending = append(s.pending, e)
+	s.trim()
+}
+
+// requeue 将发送失败的事件放回待发送事件的最前面
+func (s *sink) requeue(events []Event) {
+	s.pending = append(events, s.pending...)
+	s.trim()
+}
+
+func (s *sink) trim() {
+	if len(s.pending) <= maxPending {
+		return
+	}
+	dropped := s.pending[:len(s.pending)-maxPending]
+	for _, e := range dropped {
+		delete(s.queued, e.Key)
+	}
+	logger.Warning(fmt.Sprintf("[ notify ] too many pending events for %s, dropped %d oldest", s.name(), len(dropped)))
+	s.pending = s.pending[len(s.pending)-maxPending:]
+}
+
+func (s *sink) render(events []Event) (string, error) {
+	var b strings.Builder
+	if err := s.template.Execute(&b, map[string]interface{}{"Events": events}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (s *sink) send(events []Event) error {
+	text, err := s.render(events)
+	if err != nil {
+		return err
+	}
+	switch s.config.Type {
+	case "webhook":
+		_, err := postJSON(s.config.URL, s.config.Headers, map[string]interface{}{"text": text, "events": events})
+		return err
+	case "dingtalk":
+		return sendDingTalk(s.config, text)
+	case "feishu":
+		return sendFeishu(s.config, text)
+	case "wecom":
+		return postBot(s.config.URL, map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": text},
+		})
+	case "slack":
+		_, err := postJSON(s.config.URL, nil, map[string]string{"text": text})
+		return err
+	case "email":
+		return sendEmail(s.config, text)
+	}
+	return nil
+}
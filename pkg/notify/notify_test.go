@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+func TestFlushRetriesFailedSend(t *testing.T) {
+	logger.InitLogger("info")
+	var (
+		mu       sync.Mutex
+		fail     = true
+		received [][]Event
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var body struct {
+			Events []Event `json:"events"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		received = append(received, body.Events)
+	}))
+	defer srv.Close()
+
+	config := public.NotificationConfig{Sinks: []public.NotificationSink{
+		{Name: "hook", Type: "webhook", URL: srv.URL},
+		// 只订阅域名到期事件的渠道不接收其他事件
+		{Name: "expiry", Type: "webhook", URL: srv.URL, Events: []string{EventDomainExpiring}},
+	}}
+	n := &Notifier{config: config}
+	for _, c := range config.Sinks {
+		s, err := newSink(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n.sinks = append(n.sinks, s)
+	}
+	defaultNotifier = n
+	t.Cleanup(func() { defaultNotifier = nil })
+
+	event := Event{Type: EventRefreshFailed, CloudProvider: "tencent", CloudName: "test", Message: "list domains failed"}
+	Publish(event)
+	Publish(event)
+	n.flush()
+	if len(received) != 0 {
+		t.Fatalf("got %d deliveries while the sink fails", len(received))
+	}
+
+	// 发送失败的事件不记入去重记录，下一个窗口重新发送，期间重复提交的事件不会重复排队
+	Publish(event)
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	n.flush()
+	if len(received) != 1 || len(received[0]) != 1 || received[0][0].Message != event.Message {
+		t.Fatalf("unexpected deliveries %+v", received)
+	}
+
+	// 发送成功后去重窗口内不再发送
+	Publish(event)
+	n.flush()
+	if len(received) != 1 {
+		t.Errorf("got %d deliveries, want the event deduplicated", len(received))
+	}
+}
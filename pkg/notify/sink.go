@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/go-resty/resty/v2"
+	"github.com/tidwall/gjson"
+)
+
+var httpClient = resty.New().SetTimeout(10 * time.Second)
+
+// smtpTimeout 发送一封邮件的整体超时
+var smtpTimeout = time.Minute
+
+// postJSON 以 JSON 格式发送消息，HTTP 状态码非 2xx 时返回错误
+func postJSON(address string, headers map[string]string, body interface{}) (*resty.Response, error) {
+	rsp, err := httpClient.R().SetHeaders(headers).SetHeader("Content-Type", "application/json").SetBody(body).Post(address)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.IsError() {
+		return nil, fmt.Errorf("status %s: %s", rsp.Status(), rsp.String())
+	}
+	return rsp, nil
+}
+
+// postBot 发送机器人消息，钉钉、企业微信、飞书在请求失败时仍返回 200，错误码在响应体中
+func postBot(address string, body interface{}) error {
+	rsp, err := postJSON(address, nil, body)
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{"errcode", "code"} {
+		if code := gjson.Get(rsp.String(), key); code.Exists() && code.Int() != 0 {
+			return fmt.Errorf("response: %s", rsp.String())
+		}
+	}
+	return nil
+}
+
+// sendDingTalk 发送钉钉机器人消息，配置了 secret 时按加签方式在地址中附加 timestamp 与 sign
+func sendDingTalk(c public.NotificationSink, text string) error {
+	address := c.URL
+	if c.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(c.Secret))
+		mac.Write([]byte(timestamp + "\n" + c.Secret))
+		sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		address = appendQuery(address, url.Values{"timestamp": {timestamp}, "sign": {sign}})
+	}
+	return postBot(address, map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": text},
+	})
+}
+
+// sendFeishu 发送飞书机器人消息，配置了 secret 时在消息体中附加 timestamp 与 sign
+func sendFeishu(c public.NotificationSink, text string) error {
+	body := map[string]interface{}{
+		"msg_type": "text",
+		"content":  map[string]string{"text": text},
+	}
+	if c.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(timestamp+"\n"+c.Secret))
+		body["timestamp"] = timestamp
+		body["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	return postBot(c.URL, body)
+}
+
+func appendQuery(address string, values url.Values) string {
+	if strings.Contains(address, "?") {
+		return address + "&" + values.Encode()
+	}
+	return address + "?" + values.Encode()
+}
+
+// sendEmail 通过 SMTP 发送邮件，465 端口使用 TLS 连接，其他端口在服务器支持时使用 STARTTLS
+func sendEmail(c public.NotificationSink, text string) error {
+	port := c.SMTPPort
+	if port == 0 {
+		port = 25
+	}
+	addr := net.JoinHostPort(c.SMTPHost, strconv.Itoa(port))
+	var auth smtp.Auth
+	if c.Username != "" {
+		auth = smtp.PlainAuth("", c.Username, c.Password, c.SMTPHost)
+	}
+	subject := "Cloud DNS Exporter Notification"
+	msg := strings.Join([]string{
+		"From: " + c.From,
+		"To: " + strings.Join(c.To, ","),
+		"Subject: " + subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		text,
+	}, "\r\n")
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if port == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: c.SMTPHost})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	// 服务器无响应时避免一直阻塞后续通知的发送
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, c.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: c.SMTPHost}); err != nil {
+				return err
+			}
+		}
+	}
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(c.From); err != nil {
+		return err
+	}
+	for _, to := range c.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
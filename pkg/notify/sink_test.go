@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+)
+
+func TestSendEmailTimeout(t *testing.T) {
+	// 接受连接但不发送问候语的 SMTP 服务器
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	timeout := smtpTimeout
+	smtpTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { smtpTimeout = timeout })
+
+	addr := ln.Addr().(*net.TCPAddr)
+	config := public.NotificationSink{SMTPHost: "127.0.0.1", SMTPPort: addr.Port, From: "a@example.com", To: []string{"b@example.com"}}
+	done := make(chan error, 1)
+	go func() { done <- sendEmail(config, "test") }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("want error from an unresponsive server")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("sendEmail did not time out")
+	}
+}
@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+// AkamaiDNS 通过 Akamai Edge DNS 的 Config API 获取域与记录，使用 EdgeGrid 签名认证
+type AkamaiDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+type akamaiMetadata struct {
+	Page          int `json:"page"`
+	PageSize      int `json:"pageSize"`
+	TotalElements int `json:"totalElements"`
+}
+
+type akamaiZone struct {
+	Zone            string `json:"zone"`
+	Type            string `json:"type"`
+	Comment         string `json:"comment"`
+	SignAndServe    bool   `json:"signAndServe"`
+	ActivationState string `json:"activationState"`
+}
+
+type akamaiRecordSet struct {
+	Name  string   `json:"name"`
+	Type  string   `json:"type"`
+	TTL   int      `json:"ttl"`
+	Rdata []string `json:"rdata"`
+}
+
+// NewAkamaiClient 初始化客户端，endpoint 为 API 客户端凭据中的 host
+func NewAkamaiClient(endpoint, clientToken, clientSecret, accessToken string) (*restapi.Client, error) {
+	if !strings.HasPrefix(endpoint, "http") {
+		endpoint = "https://" + endpoint
+	}
+	return restapi.NewClient(strings.TrimSuffix(endpoint, "/")+"/config-dns/v2",
+		restapi.WithEdgeGrid(clientToken, clientSecret, accessToken))
+}
+
+// NewAkamaiDNS 创建 AkamaiDNS 实例
+func NewAkamaiDNS(account public.Account) (*AkamaiDNS, error) {
+	if account.Endpoint == "" {
+		return nil, errors.New("missing akamai host")
+	}
+	client, err := NewAkamaiClient(account.Endpoint, account.SecretID, account.SecretKey, account.Options["accessToken"])
+	if err != nil {
+		return nil, err
+	}
+	return &AkamaiDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (a *AkamaiDNS) ListDomains() ([]Domain, error) {
+	ad, err := NewAkamaiDNS(a.account)
+	if err != nil {
+		return nil, err
+	}
+	a.client = ad.client
+	var dataObj []Domain
+	zones, err := a.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range zones {
+		status := "enable"
+		if v.ActivationState != "" && v.ActivationState != "ACTIVE" {
+			status = strings.ToLower(v.ActivationState)
+		}
+		dataObj = append(dataObj, Domain{
+			CloudProvider: a.account.CloudProvider,
+			CloudName:     a.account.CloudName,
+			DomainID:      v.Zone,
+			DomainName:    v.Zone,
+			DomainRemark:  v.Comment,
+			DomainStatus:  status,
+			Labels: map[string]string{
+				"kind":   v.Type,
+				"dnssec": strconv.FormatBool(v.SignAndServe),
+			},
+		})
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (a *AkamaiDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	ad, err := NewAkamaiDNS(a.account)
+	if err != nil {
+		return nil, err
+	}
+	a.client = ad.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + a.account.CloudProvider + "_" + a.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]akamaiRecordSet)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			<-ticker.C
+			records, err := a.getRecordList(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", a.account.CloudProvider, a.account.CloudName, err))
+			}
+			mu.Lock()
+			results[domain] = records
+			mu.Unlock()
+		}(domain.DomainName)
+	}
+	wg.Wait()
+	for domain, recordSets := range results {
+		for _, v := range recordSets {
+			name := v.Name
+			if name == domain {
+				name = "@"
+			} else {
+				name = strings.TrimSuffix(name, "."+domain)
+			}
+			for _, rdata := range v.Rdata {
+				value := strings.TrimSuffix(rdata, ".")
+				dataObj = append(dataObj, Record{
+					CloudProvider: a.account.CloudProvider,
+					CloudName:     a.account.CloudName,
+					DomainName:    domain,
+					RecordID:      recordID(domain, v.Type, name, value),
+					RecordType:    v.Type,
+					RecordName:    name,
+					RecordValue:   value,
+					RecordTTL:     strconv.Itoa(v.TTL),
+					RecordStatus:  "enable",
+					FullRecord:    v.Name,
+				})
+			}
+		}
+	}
+	return dataObj, nil
+}
+
+// https://techdocs.akamai.com/edge-dns/reference/get-zones
+// getDomainList 获取域列表
+func (a *AkamaiDNS) getDomainList() (rst []akamaiZone, err error) {
+	page := 1
+	for {
+		var resp struct {
+			Metadata akamaiMetadata `json:"metadata"`
+			Zones    []akamaiZone   `json:"zones"`
+		}
+		if err := a.client.Get("/zones", a.pageParams(page), &resp); err != nil {
+			return nil, err
+		}
+		rst = append(rst, resp.Zones...)
+		if len(resp.Zones) == 0 || resp.Metadata.Page*resp.Metadata.PageSize >= resp.Metadata.TotalElements {
+			break
+		}
+		page++
+	}
+	return
+}
+
+// https://techdocs.akamai.com/edge-dns/reference/get-zones-zone-recordsets
+// getRecordList 获取记录集列表
+func (a *AkamaiDNS) getRecordList(domain string) (rst []akamaiRecordSet, err error) {
+	page := 1
+	for {
+		var resp struct {
+			Metadata   akamaiMetadata    `json:"metadata"`
+			Recordsets []akamaiRecordSet `json:"recordsets"`
+		}
+		if err := a.client.Get("/zones/"+domain+"/recordsets", a.pageParams(page), &resp); err != nil {
+			return rst, err
+		}
+		rst = append(rst, resp.Recordsets...)
+		if len(resp.Recordsets) == 0 || resp.Metadata.Page*resp.Metadata.PageSize >= resp.Metadata.TotalElements {
+			break
+		}
+		page++
+	}
+	return
+}
+
+// pageParams 分页参数，配置了 accountSwitchKey 时一并附加
+func (a *AkamaiDNS) pageParams(page int) url.Values {
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	params.Set("pageSize", "500")
+	if key := a.account.Options["accountSwitchKey"]; key != "" {
+		params.Set("accountSwitchKey", key)
+	}
+	return params
+}
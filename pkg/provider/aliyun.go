@@ -79,6 +79,7 @@ func (a *AliyunDNS) ListDomains() ([]Domain, error) {
 			DomainName:      tea.StringValue(v.DomainName),
 			DomainRemark:    tea.StringValue(v.Remark),
 			DomainStatus:    "enable",
+			Labels:          map[string]string{"group": tea.StringValue(v.GroupName)},
 			CreatedDate:     domainCreateAndExpiryDate.CreatedDate,
 			ExpiryDate:      domainCreateAndExpiryDate.ExpiryDate,
 			DaysUntilExpiry: domainCreateAndExpiryDate.DaysUntilExpiry,
@@ -114,9 +115,13 @@ func (a *AliyunDNS) ListRecords() ([]Record, error) {
 		return nil, err
 	}
 	results := make(map[string][]*alidns.DescribeDomainRecordsResponseBodyDomainRecordsRecord)
+	lines := make(map[string]map[string]string)
+	groups := make(map[string]string)
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 	for _, domain := range domains {
+		// 阿里云的分组是域名级别的，记录的分组即其所属域名的分组
+		groups[domain.DomainName] = domain.Labels["group"]
 		wg.Add(1)
 		go func(domain string) {
 			defer wg.Done()
@@ -125,28 +130,46 @@ func (a *AliyunDNS) ListRecords() ([]Record, error) {
 			if err != nil {
 				logger.Error("get record list failed: %v", err)
 			}
+			recordLines, err := a.getRecordLines(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record lines failed: %v", a.account.CloudProvider, a.account.CloudName, err))
+			}
 			mu.Lock()
 			results[domain] = records
+			lines[domain] = recordLines
 			mu.Unlock()
 		}(domain.DomainName)
 	}
 	wg.Wait()
 	for domain, records := range results {
 		for _, v := range records {
+			// 记录中的 Line 为线路代码，如 telecom，名称需要通过线路列表获取
+			lineName := lines[domain][tea.StringValue(v.Line)]
+			if lineName == "" {
+				lineName = tea.StringValue(v.Line)
+			}
+			var preference string
+			if tea.StringValue(v.Type) == "MX" {
+				preference = fmt.Sprintf("%d", tea.Int64Value(v.Priority))
+			}
 			dataObj = append(dataObj, Record{
-				CloudProvider: a.account.CloudProvider,
-				CloudName:     a.account.CloudName,
-				DomainName:    domain,
-				RecordID:      tea.StringValue(v.RecordId),
-				RecordType:    tea.StringValue(v.Type),
-				RecordName:    tea.StringValue(v.RR),
-				RecordValue:   tea.StringValue(v.Value),
-				RecordTTL:     fmt.Sprintf("%d", tea.Int64Value(v.TTL)),
-				RecordWeight:  fmt.Sprintf("%d", tea.Int32Value(v.Weight)),
-				RecordStatus:  oneStatus(tea.StringValue(v.Status)),
-				RecordRemark:  tea.StringValue(v.Remark),
-				UpdateTime:    carbon.CreateFromTimestampMilli(tea.Int64Value(v.UpdateTimestamp)).ToDateTimeString(),
-				FullRecord:    tea.StringValue(v.RR) + "." + domain,
+				CloudProvider:    a.account.CloudProvider,
+				CloudName:        a.account.CloudName,
+				DomainName:       domain,
+				RecordID:         tea.StringValue(v.RecordId),
+				RecordType:       tea.StringValue(v.Type),
+				RecordName:       tea.StringValue(v.RR),
+				RecordValue:      tea.StringValue(v.Value),
+				RecordTTL:        fmt.Sprintf("%d", tea.Int64Value(v.TTL)),
+				RecordWeight:     fmt.Sprintf("%d", tea.Int32Value(v.Weight)),
+				RecordLine:       lineName,
+				RecordLineID:     tea.StringValue(v.Line),
+				RecordPreference: preference,
+				RecordGroup:      groups[domain],
+				RecordStatus:     oneStatus(tea.StringValue(v.Status)),
+				RecordRemark:     tea.StringValue(v.Remark),
+				UpdateTime:       carbon.CreateFromTimestampMilli(tea.Int64Value(v.UpdateTimestamp)).ToDateTimeString(),
+				FullRecord:       tea.StringValue(v.RR) + "." + domain,
 			})
 		}
 	}
@@ -200,6 +223,35 @@ func (a *AliyunDNS) getRecordList(domain string) (rst []*alidns.DescribeDomainRe
 	return
 }
 
+// aliyunRecordLines 缓存各域名支持的解析线路，线路表很少变化
+var aliyunRecordLines = newLookupCache[map[string]string](24 * time.Hour)
+
+// https://next.api.aliyun.com/document/Alidns/2015-01-09/DescribeSupportLines
+// getRecordLines 获取域名支持的解析线路，返回线路代码到线路名称的映射
+func (a *AliyunDNS) getRecordLines(domain string) (map[string]string, error) {
+	key := a.account.CloudProvider + "_" + a.account.CloudName + "_" + domain
+	return aliyunRecordLines.get(key, func() (map[string]string, error) {
+		return a.describeSupportLines(domain)
+	})
+}
+
+func (a *AliyunDNS) describeSupportLines(domain string) (map[string]string, error) {
+	resp, err := a.client.DescribeSupportLines(&alidns.DescribeSupportLinesRequest{
+		DomainName: tea.String(domain),
+	})
+	if err != nil {
+		return nil, err
+	}
+	rst := make(map[string]string)
+	if resp.Body.RecordLines == nil {
+		return rst, nil
+	}
+	for _, v := range resp.Body.RecordLines.RecordLine {
+		rst[tea.StringValue(v.LineCode)] = tea.StringValue(v.LineDisplayName)
+	}
+	return rst, nil
+}
+
 // https://next.api.aliyun.com/document/Domain/2018-01-29/QueryDomainList
 // getDomainNameList 获取域名列表
 func (a *AliyunDNS) getDomainNameList() (rst []*domain.QueryDomainListResponseBodyDataDomain, err error) {
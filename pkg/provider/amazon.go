@@ -3,96 +3,231 @@ package provider
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/alibabacloud-go/tea/tea"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/route53/types"
 	"github.com/aws/aws-sdk-go-v2/service/route53domains"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
 	"github.com/golang-module/carbon/v2"
 	"golang.org/x/net/context"
 )
 
 type AmazonDNS struct {
 	account public.Account
-	client  *route53.Client
+	clients []*awsClient
+}
+
+// awsClient 一个 aws 账号的客户端，开启 organization 时每个成员账号各有一个
+type awsClient struct {
+	accountID string
+	dns       *route53.Client
+	domains   *route53domains.Client
 }
 
 const region = "us-east-1"
 
-func NewAwsDnsClient(secretID string, secretKey string) *route53.Client {
+// awsOrganizationRole 开启 organization 时默认在成员账号中扮演的角色
+const awsOrganizationRole = "OrganizationAccountAccessRole"
+
+// awsOrganizationCacheTTL 成员账号客户端的缓存时间，客户端的凭证过期后会自动重新扮演角色
+const awsOrganizationCacheTTL = time.Hour
+
+// awsOrganizationClients 缓存 organization 模式下各账号的成员账号客户端，避免每轮刷新都枚举账号
+var awsOrganizationClients = struct {
+	sync.Mutex
+	entries map[string]awsClientsEntry
+}{entries: make(map[string]awsClientsEntry)}
+
+type awsClientsEntry struct {
+	clients []*awsClient
+	expires time.Time
+}
+
+func NewAwsDnsClient(credentials aws.CredentialsProvider, region string) *route53.Client {
 	return route53.New(route53.Options{
-		Credentials:      aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(secretID, secretKey, "")),
+		Credentials:      credentials,
 		Region:           region,
 		RetryMaxAttempts: 3,
 	})
 }
 
-func NewAwsDns(account public.Account) *AmazonDNS {
-	client := NewAwsDnsClient(account.SecretID, account.SecretKey)
-	return &AmazonDNS{
-		account: account,
-		client:  client,
-	}
-}
-
-func NewAwsDomainClient(secretID string, secretKey string) *route53domains.Client {
+// NewAwsDomainClient 域名注册接口仅在 us-east-1 提供
+func NewAwsDomainClient(credentials aws.CredentialsProvider) *route53domains.Client {
 	return route53domains.New(route53domains.Options{
-		Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(secretID, secretKey, "")),
+		Credentials: credentials,
 		Region:      region,
 	})
 }
 
+// NewAwsCredentials 获取账号凭证
+// 配置了 secretId/secretKey 时使用静态密钥，否则使用默认凭证链(环境变量、profile、IRSA、实例角色等)
+// 配置了 roleArn 时在此基础上扮演该角色，同时配置 webIdentityTokenFile 时通过 web identity 扮演
+func NewAwsCredentials(account public.Account) (aws.CredentialsProvider, error) {
+	opts := account.Options
+	var base aws.CredentialsProvider
+	if account.SecretID != "" && account.SecretKey != "" {
+		base = aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(account.SecretID, account.SecretKey, opts["sessionToken"]))
+	} else {
+		loadOptions := []func(*config.LoadOptions) error{config.WithRegion(awsRegion(account))}
+		if opts["profile"] != "" {
+			loadOptions = append(loadOptions, config.WithSharedConfigProfile(opts["profile"]))
+		}
+		cfg, err := config.LoadDefaultConfig(context.Background(), loadOptions...)
+		if err != nil {
+			return nil, err
+		}
+		base = cfg.Credentials
+	}
+	roleArn := opts["roleArn"]
+	if roleArn == "" {
+		return base, nil
+	}
+	if tokenFile := opts["webIdentityTokenFile"]; tokenFile != "" {
+		stsClient := sts.New(sts.Options{Region: awsRegion(account)})
+		return aws.NewCredentialsCache(stscreds.NewWebIdentityRoleProvider(stsClient, roleArn, stscreds.IdentityTokenFile(tokenFile), func(o *stscreds.WebIdentityRoleOptions) {
+			o.RoleSessionName = awsRoleSessionName(account)
+		})), nil
+	}
+	return awsAssumeRole(account, base, roleArn), nil
+}
+
+// awsAssumeRole 使用 base 凭证扮演 roleArn
+func awsAssumeRole(account public.Account, base aws.CredentialsProvider, roleArn string) aws.CredentialsProvider {
+	stsClient := sts.New(sts.Options{Region: awsRegion(account), Credentials: base})
+	return aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, roleArn, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = awsRoleSessionName(account)
+		if externalID := account.Options["externalId"]; externalID != "" {
+			o.ExternalID = aws.String(externalID)
+		}
+	}))
+}
+
+func awsRegion(account public.Account) string {
+	if r := account.Options["region"]; r != "" {
+		return r
+	}
+	return region
+}
+
+func awsRoleSessionName(account public.Account) string {
+	if name := account.Options["roleSessionName"]; name != "" {
+		return name
+	}
+	return "cloud_dns_exporter"
+}
+
+func NewAwsDns(account public.Account) (*AmazonDNS, error) {
+	creds, err := NewAwsCredentials(account)
+	if err != nil {
+		return nil, err
+	}
+	a := &AmazonDNS{account: account}
+	if account.Options["organization"] != "true" {
+		a.clients = []*awsClient{{
+			dns:     NewAwsDnsClient(creds, awsRegion(account)),
+			domains: NewAwsDomainClient(creds),
+		}}
+		return a, nil
+	}
+	key := account.CloudProvider + "_" + account.CloudName
+	awsOrganizationClients.Lock()
+	defer awsOrganizationClients.Unlock()
+	if entry, ok := awsOrganizationClients.entries[key]; ok && time.Now().Before(entry.expires) {
+		a.clients = entry.clients
+		return a, nil
+	}
+	a.clients, err = a.getOrganizationClients(creds)
+	if err != nil {
+		return nil, err
+	}
+	awsOrganizationClients.entries[key] = awsClientsEntry{clients: a.clients, expires: time.Now().Add(awsOrganizationCacheTTL)}
+	return a, nil
+}
+
 func (a *AmazonDNS) ListDomains() ([]Domain, error) {
-	ad := NewAwsDns(public.Account{
-		CloudProvider: a.account.CloudProvider,
-		CloudName:     a.account.CloudName,
-		SecretID:      a.account.SecretID,
-		SecretKey:     a.account.SecretKey,
-	})
-	a.client = ad.client
+	ad, err := NewAwsDns(a.account)
+	if err != nil {
+		return nil, err
+	}
+	a.clients = ad.clients
 	var (
 		dataObj []Domain
 		wg      sync.WaitGroup
 		mu      sync.Mutex
 	)
-	domains, err := a.getDomainList()
-	if err != nil {
-		return nil, err
-	}
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
-	for _, domain := range domains {
-		wg.Add(1)
-		go func(domain types.HostedZone) {
-			defer wg.Done()
-			<-ticker.C
-			domainName := strings.TrimSuffix(tea.StringValue(domain.Name), ".")
-			domainCreateAndExpiryDate := a.getDomainCreateAndExpiryDate(domainName)
-			mu.Lock()
-			dataObj = append(dataObj, Domain{
-				CloudProvider:   a.account.CloudProvider,
-				CloudName:       a.account.CloudName,
-				DomainID:        strings.TrimPrefix(tea.StringValue(domain.Id), "/hostedzone/"),
-				DomainName:      domainName,
-				DomainRemark:    tea.StringValue(nil),
-				DomainStatus:    "enable",
-				CreatedDate:     domainCreateAndExpiryDate.CreatedDate,
-				ExpiryDate:      domainCreateAndExpiryDate.ExpiryDate,
-				DaysUntilExpiry: domainCreateAndExpiryDate.DaysUntilExpiry,
-			})
-			mu.Unlock()
-		}(domain)
+	for _, c := range a.clients {
+		domains, err := a.getDomainList(c)
+		if err != nil {
+			// 开启 organization 时单个成员账号失败不影响其他账号
+			if len(a.clients) == 1 {
+				return nil, err
+			}
+			logger.Error(fmt.Sprintf("[ %s_%s ] get domain list of account %s failed: %v", a.account.CloudProvider, a.account.CloudName, c.accountID, err))
+			continue
+		}
+		for _, domain := range domains {
+			wg.Add(1)
+			go func(c *awsClient, domain types.HostedZone) {
+				defer wg.Done()
+				<-ticker.C
+				d := a.toDomain(c, domain)
+				mu.Lock()
+				dataObj = append(dataObj, d)
+				mu.Unlock()
+			}(c, domain)
+		}
 	}
 	wg.Wait()
 	return dataObj, nil
 }
 
+// toDomain 转换托管区域
+func (a *AmazonDNS) toDomain(c *awsClient, domain types.HostedZone) Domain {
+	domainName := strings.TrimSuffix(tea.StringValue(domain.Name), ".")
+	domainID := strings.TrimPrefix(tea.StringValue(domain.Id), "/hostedzone/")
+	d := Domain{
+		CloudProvider: a.account.CloudProvider,
+		CloudName:     a.account.CloudName,
+		DomainID:      domainID,
+		DomainName:    domainName,
+		DomainStatus:  "enable",
+		Labels:        map[string]string{"private_zone": "false"},
+	}
+	if c.accountID != "" {
+		d.Labels["aws_account_id"] = c.accountID
+	}
+	if domain.Config != nil {
+		d.DomainRemark = tea.StringValue(domain.Config.Comment)
+		if domain.Config.PrivateZone {
+			// 私有托管区域没有注册信息，需要通过托管区域详情获取关联的 VPC
+			d.Labels["private_zone"] = "true"
+			d.Labels["vpcs"] = a.getDomainVPCs(c, domainID)
+		}
+	}
+	if d.Labels["private_zone"] == "false" {
+		domainCreateAndExpiryDate := a.getDomainCreateAndExpiryDate(c, domainName)
+		d.CreatedDate = domainCreateAndExpiryDate.CreatedDate
+		d.ExpiryDate = domainCreateAndExpiryDate.ExpiryDate
+		d.DaysUntilExpiry = domainCreateAndExpiryDate.DaysUntilExpiry
+	}
+	return d
+}
+
 func (a *AmazonDNS) ListRecords() ([]Record, error) {
 	var (
 		dataObj []Record
@@ -100,13 +235,15 @@ func (a *AmazonDNS) ListRecords() ([]Record, error) {
 		wg      sync.WaitGroup
 		mu      sync.Mutex
 	)
-	ad := NewAwsDns(public.Account{
-		CloudProvider: a.account.CloudProvider,
-		CloudName:     a.account.CloudName,
-		SecretID:      a.account.SecretID,
-		SecretKey:     a.account.SecretKey,
-	})
-	a.client = ad.client
+	ad, err := NewAwsDns(a.account)
+	if err != nil {
+		return nil, err
+	}
+	a.clients = ad.clients
+	clients := make(map[string]*awsClient, len(a.clients))
+	for _, c := range a.clients {
+		clients[c.accountID] = c
+	}
 	rst, err := public.Cache.Get(public.DomainList + "_" + a.account.CloudProvider + "_" + a.account.CloudName)
 	if err != nil {
 		return nil, err
@@ -115,68 +252,219 @@ func (a *AmazonDNS) ListRecords() ([]Record, error) {
 	if err != nil {
 		return nil, err
 	}
+	// 公有与私有托管区域、不同成员账号中的托管区域都可以同名，按账号与托管区域 ID 区分
 	results := make(map[string][]types.ResourceRecordSet)
+	zones := make(map[string]Domain)
+	accountIDs := make(map[string]string)
 	ticker := time.NewTicker(100 * time.Millisecond)
 	for _, domain := range domains {
+		c, ok := clients[domain.Labels["aws_account_id"]]
+		if !ok {
+			continue
+		}
 		wg.Add(1)
 		// aws 接口并发限制
 		time.Sleep(200 * time.Millisecond)
-		go func(domain Domain) {
+		go func(c *awsClient, domain Domain) {
 			defer wg.Done()
 			<-ticker.C
-			records, err := a.getRecordList(domain.DomainID)
+			records, err := a.getRecordList(c, domain.DomainID)
 			if err != nil {
-				fmt.Printf("get record list failed: %s\n", err)
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list of %s (%s) failed: %v", a.account.CloudProvider, a.account.CloudName, domain.DomainName, domain.DomainID, err))
 			}
+			key := c.accountID + "/" + domain.DomainID
 			mu.Lock()
-			results[domain.DomainName] = records
+			results[key] = records
+			zones[key] = domain
+			accountIDs[key] = c.accountID
 			mu.Unlock()
-		}(domain)
+		}(c, domain)
 	}
 	wg.Wait()
-	for domain, record := range results {
-		for _, record := range record {
+	for key, records := range results {
+		domain, zoneID := zones[key].DomainName, zones[key].DomainID
+		for _, record := range records {
+			labels := awsRecordLabels(record)
+			if accountIDs[key] != "" {
+				labels["aws_account_id"] = accountIDs[key]
+			}
+			// aws 返回的是以 . 结尾的完整域名，且 * 等字符会被转义为 \052 的形式
+			full := unescapeAwsName(strings.TrimSuffix(tea.StringValue(record.Name), "."))
+			name := "@"
+			if full != domain {
+				name = strings.TrimSuffix(full, "."+domain)
+			}
 			recordInfo := Record{
 				CloudProvider: a.account.CloudProvider,
 				CloudName:     a.account.CloudName,
 				DomainName:    domain,
-				RecordID:      public.GetID(),
 				RecordType:    string(record.Type),
-				RecordWeight:  fmt.Sprintf("%d", record.Weight),
+				RecordName:    name,
 				RecordStatus:  oneStatus("enable"),
-				RecordRemark:  tea.StringValue(nil),
-				UpdateTime:    carbon.CreateFromTimestampMilli(tea.Int64Value(nil)).ToDateTimeString(),
-				FullRecord:    strings.TrimSuffix(tea.StringValue(record.Name), "."),
+				FullRecord:    full,
+				Labels:        labels,
 			}
-			// aws域名返回完整域名处理
-			recordName := strings.TrimSuffix(tea.StringValue(record.Name), ".")
-			if len(strings.Split(recordName, ".")) > 2 {
-				recordInfo.RecordName = strings.TrimSuffix(strings.Replace(recordName, domain, "", 1), ".")
-			} else {
-				recordInfo.RecordName = "@"
+			if record.Weight != nil {
+				recordInfo.RecordWeight = strconv.FormatInt(*record.Weight, 10)
 			}
-			if record.TTL == nil {
+			if record.TTL != nil {
+				recordInfo.RecordTTL = strconv.FormatInt(*record.TTL, 10)
+			} else if record.AliasTarget == nil {
 				recordInfo.RecordTTL = "300"
-			} else {
-				recordInfo.RecordTTL = fmt.Sprintf("%d", *record.TTL)
 			}
-			if record.ResourceRecords != nil {
-				for _, record := range record.ResourceRecords {
-					recordInfo.RecordValue = tea.StringValue(record.Value)
-					dataObj = append(dataObj, recordInfo)
-				}
-			} else {
-				fmt.Println("record value is nil", record)
+			var values []string
+			if record.AliasTarget != nil {
+				// 别名记录没有 ResourceRecords，记录值为别名目标，TTL 继承自目标资源
+				values = append(values, strings.TrimSuffix(tea.StringValue(record.AliasTarget.DNSName), "."))
+			}
+			for _, rr := range record.ResourceRecords {
+				values = append(values, tea.StringValue(rr.Value))
+			}
+			for _, value := range values {
+				r := recordInfo
+				r.RecordValue = value
+				// 同名记录通过 SetIdentifier 区分不同的路由策略，同名托管区域通过托管区域 ID 区分
+				r.RecordID = recordID(domain, r.RecordType, name, value+"|"+tea.StringValue(record.SetIdentifier)+"|"+zoneID)
+				dataObj = append(dataObj, r)
 			}
 		}
 	}
 	return dataObj, nil
 }
 
+// awsRecordLabels 别名与路由策略等信息
+func awsRecordLabels(record types.ResourceRecordSet) map[string]string {
+	labels := map[string]string{
+		"alias":          "false",
+		"routing_policy": "simple",
+	}
+	if record.AliasTarget != nil {
+		labels["alias"] = "true"
+		labels["alias_hosted_zone_id"] = tea.StringValue(record.AliasTarget.HostedZoneId)
+		labels["evaluate_target_health"] = strconv.FormatBool(record.AliasTarget.EvaluateTargetHealth)
+	}
+	switch {
+	case record.Weight != nil:
+		labels["routing_policy"] = "weighted"
+	case record.Region != "":
+		labels["routing_policy"] = "latency"
+		labels["region"] = string(record.Region)
+	case record.Failover != "":
+		labels["routing_policy"] = "failover"
+		labels["failover"] = string(record.Failover)
+	case record.GeoLocation != nil:
+		labels["routing_policy"] = "geolocation"
+		var location []string
+		for _, v := range []*string{record.GeoLocation.ContinentCode, record.GeoLocation.CountryCode, record.GeoLocation.SubdivisionCode} {
+			if v != nil {
+				location = append(location, *v)
+			}
+		}
+		labels["geo_location"] = strings.Join(location, "/")
+	case record.GeoProximityLocation != nil:
+		labels["routing_policy"] = "geoproximity"
+		if record.GeoProximityLocation.AWSRegion != nil {
+			labels["region"] = *record.GeoProximityLocation.AWSRegion
+		} else if record.GeoProximityLocation.LocalZoneGroup != nil {
+			labels["region"] = *record.GeoProximityLocation.LocalZoneGroup
+		}
+	case record.CidrRoutingConfig != nil:
+		labels["routing_policy"] = "ip_based"
+		labels["cidr_collection_id"] = tea.StringValue(record.CidrRoutingConfig.CollectionId)
+		labels["cidr_location"] = tea.StringValue(record.CidrRoutingConfig.LocationName)
+	case tea.BoolValue(record.MultiValueAnswer):
+		labels["routing_policy"] = "multivalue"
+	}
+	if record.SetIdentifier != nil {
+		labels["set_identifier"] = *record.SetIdentifier
+	}
+	if record.HealthCheckId != nil {
+		labels["health_check_id"] = *record.HealthCheckId
+	}
+	return labels
+}
+
+// unescapeAwsName 还原 aws 返回的域名中以 \ddd 形式转义的字符
+func unescapeAwsName(name string) string {
+	if !strings.Contains(name, "\\") {
+		return name
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+3 < len(name) {
+			if n, err := strconv.ParseUint(name[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
+
+// https://docs.aws.amazon.com/Route53/latest/APIReference/API_GetHostedZone.html
+// getDomainVPCs 获取私有托管区域关联的 VPC，格式为 vpc-id:region
+func (a *AmazonDNS) getDomainVPCs(c *awsClient, domainId string) string {
+	output, err := c.dns.GetHostedZone(context.Background(), &route53.GetHostedZoneInput{
+		Id: tea.String(domainId),
+	})
+	if err != nil {
+		return ""
+	}
+	var vpcs []string
+	for _, v := range output.VPCs {
+		vpcs = append(vpcs, tea.StringValue(v.VPCId)+":"+string(v.VPCRegion))
+	}
+	return strings.Join(vpcs, ",")
+}
+
+// https://docs.aws.amazon.com/organizations/latest/APIReference/API_ListAccounts.html
+// getOrganizationClients 枚举组织内所有活跃的成员账号，并在每个账号中扮演 organizationRole
+func (a *AmazonDNS) getOrganizationClients(creds aws.CredentialsProvider) (rst []*awsClient, err error) {
+	// 当前凭证所属账号直接使用，无需扮演角色
+	identity, err := sts.New(sts.Options{Region: awsRegion(a.account), Credentials: creds}).GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
+	roleName := a.account.Options["organizationRole"]
+	if roleName == "" {
+		roleName = awsOrganizationRole
+	}
+	client := organizations.New(organizations.Options{Region: awsRegion(a.account), Credentials: creds})
+	paginator := organizations.NewListAccountsPaginator(client, &organizations.ListAccountsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range output.Accounts {
+			if v.Status != orgtypes.AccountStatusActive {
+				continue
+			}
+			accountID := tea.StringValue(v.Id)
+			accountCreds := creds
+			if accountID != tea.StringValue(identity.Account) {
+				partition := "aws"
+				if arn := tea.StringValue(v.Arn); strings.HasPrefix(arn, "arn:") {
+					partition = strings.Split(arn, ":")[1]
+				}
+				accountCreds = awsAssumeRole(a.account, creds, fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, accountID, roleName))
+			}
+			rst = append(rst, &awsClient{
+				accountID: accountID,
+				dns:       NewAwsDnsClient(accountCreds, awsRegion(a.account)),
+				domains:   NewAwsDomainClient(accountCreds),
+			})
+		}
+	}
+	return
+}
+
 // https://docs.aws.amazon.com/Route53/latest/APIReference/API_ListHostedZones.html
 // getDomainList 获取托管区域解析域名列表
-func (a *AmazonDNS) getDomainList() (rst []types.HostedZone, err error) {
-	client := NewAwsDnsClient(a.account.SecretID, a.account.SecretKey)
+func (a *AmazonDNS) getDomainList(c *awsClient) (rst []types.HostedZone, err error) {
+	client := c.dns
 	var Marker *string
 	for {
 		output, err := client.ListHostedZones(context.Background(), &route53.ListHostedZonesInput{
@@ -196,8 +484,8 @@ func (a *AmazonDNS) getDomainList() (rst []types.HostedZone, err error) {
 
 // https://docs.aws.amazon.com/Route53/latest/APIReference/API_ListResourceRecordSets.html
 // getRecordList 获取解析记录
-func (a *AmazonDNS) getRecordList(domainId string) (rst []types.ResourceRecordSet, err error) {
-	client := NewAwsDnsClient(a.account.SecretID, a.account.SecretKey)
+func (a *AmazonDNS) getRecordList(c *awsClient, domainId string) (rst []types.ResourceRecordSet, err error) {
+	client := c.dns
 	var startRecordIdentifier *string
 	var startRecordType types.RRType
 	var startRecordName *string
@@ -225,8 +513,8 @@ func (a *AmazonDNS) getRecordList(domainId string) (rst []types.ResourceRecordSe
 
 // 域名详情接口 https://docs.aws.amazon.com/Route53/latest/APIReference/API_domains_GetDomainDetail.html
 // getDomainCreateAndExpiryDate 获取域名创建时间、过期时间, 通过域名详情获取
-func (a *AmazonDNS) getDomainCreateAndExpiryDate(domainName string) (d Domain) {
-	client := NewAwsDomainClient(a.account.SecretID, a.account.SecretKey)
+func (a *AmazonDNS) getDomainCreateAndExpiryDate(c *awsClient, domainName string) (d Domain) {
+	client := c.domains
 	domainDetail, err := client.GetDomainDetail(context.Background(), &route53domains.GetDomainDetailInput{
 		DomainName: tea.String(domainName),
 	})
@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/alibabacloud-go/tea/tea"
 	"github.com/cloudflare/cloudflare-go"
 	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
 	"github.com/golang-module/carbon/v2"
 )
 
@@ -24,25 +26,30 @@ type Header struct {
 	ContentType string      `json:"Content-Type"`
 }
 
+// NewCloudflareDNSClient 初始化客户端，未配置邮箱时将 token 视为 API Token，否则使用旧版的 Global API Key
 func NewCloudflareDNSClient(token string, email string) (*cloudflare.API, error) {
+	if email == "" {
+		return cloudflare.NewWithAPIToken(token)
+	}
 	return cloudflare.New(token, email)
 }
 
-func NewCloudFlareDNS(account public.Account) *CloudFlareDNS {
-	client, _ := NewCloudflareDNSClient(account.SecretKey, account.SecretID)
+func NewCloudFlareDNS(account public.Account) (*CloudFlareDNS, error) {
+	client, err := NewCloudflareDNSClient(account.SecretKey, account.SecretID)
+	if err != nil {
+		return nil, err
+	}
 	return &CloudFlareDNS{
 		account: account,
 		client:  client,
-	}
+	}, nil
 }
 
 func (cf *CloudFlareDNS) ListDomains() ([]Domain, error) {
-	cfd := NewCloudFlareDNS(public.Account{
-		CloudProvider: cf.account.CloudProvider,
-		CloudName:     cf.account.CloudName,
-		SecretID:      cf.account.SecretID,
-		SecretKey:     cf.account.SecretKey,
-	})
+	cfd, err := NewCloudFlareDNS(cf.account)
+	if err != nil {
+		return nil, err
+	}
 	cf.client = cfd.client
 	var (
 		dataObj []Domain
@@ -60,24 +67,33 @@ func (cf *CloudFlareDNS) ListDomains() ([]Domain, error) {
 		go func(domain cloudflare.Zone) {
 			defer wg.Done()
 			<-ticker.C
-			domainCreateAndExpiryDate, _ := cf.getDomainCreateAndExpiryDate(domain)
+			d := Domain{
+				CloudName:     cf.account.CloudName,
+				CloudProvider: cf.account.CloudProvider,
+				DomainID:      domain.ID,
+				DomainName:    domain.Name,
+				DomainStatus:  oneStatus(domain.Status),
+				Labels: map[string]string{
+					"account_id":   domain.Account.ID,
+					"account_name": domain.Account.Name,
+					"plan":         domain.Plan.Name,
+					"paused":       strconv.FormatBool(domain.Paused),
+				},
+			}
+			// 令牌可访问多个账号时，注册信息需要在域名所属的账号下获取
+			if registrarDomain, err := cf.getRegistrarDomain(domain.Account.ID, domain.Name); err == nil {
+				d.CreatedDate = carbon.CreateFromStdTime(registrarDomain.CreatedAt).ToDateTimeString()
+				d.ExpiryDate = carbon.CreateFromStdTime(registrarDomain.ExpiresAt).ToDateTimeString()
+				d.DaysUntilExpiry = carbon.Now().DiffInDays(carbon.CreateFromStdTime(registrarDomain.ExpiresAt))
+				d.Locked = strconv.FormatBool(registrarDomain.Locked)
+			}
 			mu.Lock()
-			dataObj = append(dataObj, Domain{
-				CloudName:       domain.Name,
-				CloudProvider:   cf.account.CloudProvider,
-				CreatedDate:     domainCreateAndExpiryDate.CreatedDate,
-				DaysUntilExpiry: domainCreateAndExpiryDate.DaysUntilExpiry,
-				DomainID:        domain.ID,
-				DomainName:      domain.Name,
-				DomainRemark:    tea.StringValue(nil),
-				DomainStatus:    domain.Status,
-				ExpiryDate:      domainCreateAndExpiryDate.ExpiryDate,
-			})
+			dataObj = append(dataObj, d)
 			mu.Unlock()
 		}(domain)
 	}
 	wg.Wait()
-	return dataObj, err
+	return dataObj, nil
 }
 
 func (cf *CloudFlareDNS) ListRecords() ([]Record, error) {
@@ -87,12 +103,10 @@ func (cf *CloudFlareDNS) ListRecords() ([]Record, error) {
 		wg      sync.WaitGroup
 		mu      sync.Mutex
 	)
-	cfd := NewCloudFlareDNS(public.Account{
-		CloudProvider: cf.account.CloudProvider,
-		CloudName:     cf.account.CloudName,
-		SecretID:      cf.account.SecretID,
-		SecretKey:     cf.account.SecretKey,
-	})
+	cfd, err := NewCloudFlareDNS(cf.account)
+	if err != nil {
+		return nil, err
+	}
 	cf.client = cfd.client
 	rst, err := public.Cache.Get(public.DomainList + "_" + cf.account.CloudProvider + "_" + cf.account.CloudName)
 	if err != nil {
@@ -104,14 +118,15 @@ func (cf *CloudFlareDNS) ListRecords() ([]Record, error) {
 	}
 	results := make(map[string][]cloudflare.DNSRecord)
 	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
 	for _, domain := range domains {
 		wg.Add(1)
 		go func(domain Domain) {
 			defer wg.Done()
 			<-ticker.C
-			records, err := cf.getRecordList(domain.DomainName)
+			records, err := cf.getRecordList(domain.DomainID)
 			if err != nil {
-				fmt.Printf("cloudflare get record list error: %v", err)
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", cf.account.CloudProvider, cf.account.CloudName, err))
 				return
 			}
 			mu.Lock()
@@ -122,68 +137,70 @@ func (cf *CloudFlareDNS) ListRecords() ([]Record, error) {
 	wg.Wait()
 	for domain, records := range results {
 		for _, record := range records {
-			dataObj = append(dataObj, Record{
+			proxied := record.Proxied != nil && *record.Proxied
+			r := Record{
 				CloudName:     cf.account.CloudName,
 				CloudProvider: cf.account.CloudProvider,
 				DomainName:    domain,
 				RecordID:      record.ID,
-				RecordName:    record.Name,
+				RecordName:    "@",
 				RecordType:    record.Type,
-				RecordRemark:  tea.StringValue(nil),
+				RecordValue:   record.Content,
+				RecordRemark:  record.Comment,
 				RecordStatus:  "enable",
-				RecordTTL:     fmt.Sprintf("%d", record.TTL),
+				RecordTTL:     strconv.Itoa(record.TTL),
+				UpdateTime:    carbon.CreateFromStdTime(record.ModifiedOn).ToDateTimeString(),
 				FullRecord:    record.Name,
-			})
+				Labels: map[string]string{
+					"proxied":   strconv.FormatBool(proxied),
+					"proxiable": strconv.FormatBool(record.Proxiable),
+				},
+			}
+			if record.Name != domain {
+				r.RecordName = strings.TrimSuffix(record.Name, "."+domain)
+			}
+			if record.TTL == 1 {
+				// cloudflare 中 ttl 为 1 表示自动
+				r.RecordTTL = "auto"
+			}
+			if record.Priority != nil {
+				r.RecordPreference = strconv.Itoa(int(*record.Priority))
+			}
+			if proxied {
+				// 代理记录的证书由 cloudflare 边缘节点提供，探测时连接记录本身而不是源站地址
+				r.Labels["cert_source"] = "cloudflare_edge"
+			} else {
+				r.Labels["cert_source"] = "origin"
+			}
+			if len(record.Tags) > 0 {
+				r.Labels["tags"] = strings.Join(record.Tags, ",")
+			}
+			dataObj = append(dataObj, r)
 		}
 	}
-	return dataObj, err
+	return dataObj, nil
 }
 
-// getDomainList 获取解析域域名列表
+// https://developers.cloudflare.com/api/operations/zones-get
+// getDomainList 获取令牌可访问的所有账号下的解析域域名列表
 func (cf *CloudFlareDNS) getDomainList() (rst []cloudflare.Zone, err error) {
-	client, err := NewCloudflareDNSClient(cf.account.SecretKey, cf.account.SecretID)
-	if err != nil {
-		fmt.Printf("cloudflare client init error: %v", err)
-		return
-	}
-	zones, err := client.ListZones(context.Background())
-	if err != nil {
-		fmt.Printf("cloudflare list zones error: %v", err)
-		return
-	}
-	rst = append(rst, zones...)
-	return
+	return cf.client.ListZones(context.Background())
 }
 
-func (cf *CloudFlareDNS) getAccountId() (account cloudflare.Account, err error) {
-	client, _ := NewCloudflareDNSClient(cf.account.SecretKey, cf.account.SecretID)
-	accounts, _, err := client.Accounts(context.Background(), cloudflare.AccountsListParams{})
-	if err != nil {
-		return
-	}
-	for _, a := range accounts {
-		account = a
-	}
-	return
-}
-
-func (cf *CloudFlareDNS) getRecordList(domain string) (rst []cloudflare.DNSRecord, err error) {
+// https://developers.cloudflare.com/api/operations/dns-records-for-a-zone-list-dns-records
+// getRecordList 获取解析记录
+func (cf *CloudFlareDNS) getRecordList(zoneID string) (rst []cloudflare.DNSRecord, err error) {
 	page := 1
-	pageSize := 2
-	client, _ := NewCloudflareDNSClient(cf.account.SecretKey, cf.account.SecretID)
-	zoneID, err := client.ZoneIDByName(domain)
-	if err != nil {
-		return
-	}
+	pageSize := 100
 	for {
-		records, r, err := client.ListDNSRecords(context.Background(), cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{
+		records, r, err := cf.client.ListDNSRecords(context.Background(), cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{
 			ResultInfo: cloudflare.ResultInfo{Page: page, PerPage: pageSize},
 		})
 		if err != nil {
 			return nil, err
 		}
 		rst = append(rst, records...)
-		if page*pageSize > r.Total {
+		if page*pageSize >= r.Total {
 			break
 		}
 		page++
@@ -191,20 +208,15 @@ func (cf *CloudFlareDNS) getRecordList(domain string) (rst []cloudflare.DNSRecor
 	return
 }
 
-func (cf *CloudFlareDNS) getDomainCreateAndExpiryDate(domain cloudflare.Zone) (d Domain, err error) {
-	client, err := NewCloudflareDNSClient(cf.account.SecretKey, cf.account.SecretID)
-	if err != nil {
-		return
-	}
-	account, err := cf.getAccountId()
-	if err != nil {
-		return
-	}
-	domainInfo, err := client.RegistrarDomain(context.Background(), account.ID, domain.Name)
-	d.CreatedDate = domainInfo.CreatedAt.String()
-	d.ExpiryDate = domainInfo.ExpiresAt.String()
-	if d.ExpiryDate != "" {
-		d.DaysUntilExpiry = carbon.Now().DiffInDays(carbon.Parse(d.ExpiryDate))
+// https://developers.cloudflare.com/api/operations/registrar-domains-get-domain
+// getRegistrarDomain 获取在 cloudflare 注册的域名信息，未在 cloudflare 注册的域名返回错误
+func (cf *CloudFlareDNS) getRegistrarDomain(accountID, domainName string) (d cloudflare.RegistrarDomain, err error) {
+	if accountID == "" {
+		return d, fmt.Errorf("account of %s is unknown", domainName)
+	}
+	d, err = cf.client.RegistrarDomain(context.Background(), accountID, domainName)
+	if err == nil && d.ExpiresAt.IsZero() {
+		err = fmt.Errorf("%s is not registered with cloudflare", domainName)
 	}
 	return
 }
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+type DigitalOceanDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+type digitalOceanLinks struct {
+	Pages struct {
+		Next string `json:"next"`
+	} `json:"pages"`
+}
+
+type digitalOceanDomain struct {
+	Name string `json:"name"`
+	TTL  int    `json:"ttl"`
+}
+
+type digitalOceanRecord struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Data     string `json:"data"`
+	Priority int    `json:"priority"`
+	Port     int    `json:"port"`
+	TTL      int    `json:"ttl"`
+	Weight   int    `json:"weight"`
+}
+
+// NewDigitalOceanClient 初始化客户端
+func NewDigitalOceanClient(token string) (*restapi.Client, error) {
+	return restapi.NewClient("https://api.digitalocean.com", restapi.WithBearerToken(token))
+}
+
+// NewDigitalOceanDNS 创建 DigitalOceanDNS 实例
+func NewDigitalOceanDNS(account public.Account) (*DigitalOceanDNS, error) {
+	client, err := NewDigitalOceanClient(account.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &DigitalOceanDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (d *DigitalOceanDNS) ListDomains() ([]Domain, error) {
+	dod, err := NewDigitalOceanDNS(d.account)
+	if err != nil {
+		return nil, err
+	}
+	d.client = dod.client
+	var dataObj []Domain
+	domains, err := d.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range domains {
+		dataObj = append(dataObj, Domain{
+			CloudProvider: d.account.CloudProvider,
+			CloudName:     d.account.CloudName,
+			DomainID:      v.Name,
+			DomainName:    v.Name,
+			DomainStatus:  "enable",
+		})
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (d *DigitalOceanDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	dod, err := NewDigitalOceanDNS(d.account)
+	if err != nil {
+		return nil, err
+	}
+	d.client = dod.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + d.account.CloudProvider + "_" + d.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]digitalOceanRecord)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			<-ticker.C
+			records, err := d.getRecordList(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", d.account.CloudProvider, d.account.CloudName, err))
+			}
+			mu.Lock()
+			results[domain] = records
+			mu.Unlock()
+		}(domain.DomainName)
+	}
+	wg.Wait()
+	for domain, records := range results {
+		for _, v := range records {
+			dataObj = append(dataObj, Record{
+				CloudProvider: d.account.CloudProvider,
+				CloudName:     d.account.CloudName,
+				DomainName:    domain,
+				RecordID:      strconv.FormatInt(v.ID, 10),
+				RecordType:    v.Type,
+				RecordName:    v.Name,
+				RecordValue:   v.Data,
+				RecordTTL:     strconv.Itoa(v.TTL),
+				RecordWeight:  strconv.Itoa(v.Weight),
+				RecordStatus:  "enable",
+				FullRecord:    fullRecord(v.Name, domain),
+			})
+		}
+	}
+	return dataObj, nil
+}
+
+// https://docs.digitalocean.com/reference/api/api-reference/#operation/domains_list
+// getDomainList 获取域名列表
+func (d *DigitalOceanDNS) getDomainList() (rst []digitalOceanDomain, err error) {
+	page := 1
+	for {
+		var resp struct {
+			Domains []digitalOceanDomain `json:"domains"`
+			Links   digitalOceanLinks    `json:"links"`
+		}
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("per_page", "200")
+		if err := d.client.Get("/v2/domains", params, &resp); err != nil {
+			return nil, err
+		}
+		rst = append(rst, resp.Domains...)
+		if resp.Links.Pages.Next == "" {
+			break
+		}
+		page++
+	}
+	return
+}
+
+// https://docs.digitalocean.com/reference/api/api-reference/#operation/domains_list_records
+// getRecordList 获取记录列表
+func (d *DigitalOceanDNS) getRecordList(domain string) (rst []digitalOceanRecord, err error) {
+	page := 1
+	for {
+		var resp struct {
+			DomainRecords []digitalOceanRecord `json:"domain_records"`
+			Links         digitalOceanLinks    `json:"links"`
+		}
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("per_page", "200")
+		if err := d.client.Get("/v2/domains/"+domain+"/records", params, &resp); err != nil {
+			return rst, err
+		}
+		rst = append(rst, resp.DomainRecords...)
+		if resp.Links.Pages.Next == "" {
+			break
+		}
+		page++
+	}
+	return
+}
@@ -125,7 +125,7 @@ func (d *DNSLaDNS) ListRecords() ([]Record, error) {
 				CloudProvider: d.account.CloudProvider,
 				CloudName:     d.account.CloudName,
 				DomainName:    domain,
-				RecordID:      public.GetID(),
+				RecordID:      v.ID,
 				RecordType:    getRecordType(v.Type),
 				RecordName:    v.DisplayHost,
 				RecordValue:   v.Data,
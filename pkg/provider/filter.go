@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Filter 账号级别的域名与记录过滤规则，在写入缓存前执行，被过滤的数据不会出现在指标及证书检测中
+// 规则为逗号分隔的列表，默认按 glob 匹配(如 *.example.com)，以 / 包裹时按正则匹配(如 /^_acme-challenge/)，匹配不区分大小写
+// 正则中的逗号(如 /^a{1,3}$/)不作为分隔符
+type Filter struct {
+	includeDomains      []matcher
+	excludeDomains      []matcher
+	includeRecordTypes  []matcher
+	excludeRecordTypes  []matcher
+	includeRecordNames  []matcher
+	excludeRecordNames  []matcher
+	includeRecordStatus []matcher
+	excludeRecordStatus []matcher
+}
+
+type matcher func(string) bool
+
+// NewFilter 从账号配置中读取过滤规则，未配置任何规则时返回 nil
+func NewFilter(account map[string]string) (*Filter, error) {
+	f := &Filter{}
+	empty := true
+	for key, dst := range map[string]*[]matcher{
+		"includeDomains":      &f.includeDomains,
+		"excludeDomains":      &f.excludeDomains,
+		"includeRecordTypes":  &f.includeRecordTypes,
+		"excludeRecordTypes":  &f.excludeRecordTypes,
+		"includeRecordNames":  &f.includeRecordNames,
+		"excludeRecordNames":  &f.excludeRecordNames,
+		"includeRecordStatus": &f.includeRecordStatus,
+		"excludeRecordStatus": &f.excludeRecordStatus,
+	} {
+		for _, pattern := range splitPatterns(account[key]) {
+			m, err := newMatcher(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s pattern %q: %w", key, pattern, err)
+			}
+			*dst = append(*dst, m)
+			empty = false
+		}
+	}
+	if empty {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// FilterDomains 过滤域名列表
+func (f *Filter) FilterDomains(domains []Domain) []Domain {
+	if f == nil {
+		return domains
+	}
+	var rst []Domain
+	for _, d := range domains {
+		if f.matchDomain(d.DomainName) {
+			rst = append(rst, d)
+		}
+	}
+	return rst
+}
+
+// FilterRecords 过滤记录列表，所属域名被过滤的记录同样会被过滤
+func (f *Filter) FilterRecords(records []Record) []Record {
+	if f == nil {
+		return records
+	}
+	var rst []Record
+	for _, r := range records {
+		if !f.matchDomain(r.DomainName) ||
+			!matchList(f.includeRecordTypes, f.excludeRecordTypes, r.RecordType) ||
+			!matchList(f.includeRecordNames, f.excludeRecordNames, r.RecordName) ||
+			!matchList(f.includeRecordStatus, f.excludeRecordStatus, r.RecordStatus) {
+			continue
+		}
+		rst = append(rst, r)
+	}
+	return rst
+}
+
+func (f *Filter) matchDomain(name string) bool {
+	return matchList(f.includeDomains, f.excludeDomains, name)
+}
+
+// matchList include 为空时默认全部保留，exclude 优先于 include
+func matchList(include, exclude []matcher, value string) bool {
+	value = strings.ToLower(value)
+	for _, m := range exclude {
+		if m(value) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, m := range include {
+		if m(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPatterns 按逗号拆分规则列表，以 / 开头的正则在遇到其后紧跟逗号或结尾的 / 之前不拆分
+func splitPatterns(s string) (rst []string) {
+	var (
+		cur     strings.Builder
+		inRegex bool
+	)
+	flush := func() {
+		if v := strings.TrimSpace(cur.String()); v != "" {
+			rst = append(rst, v)
+		}
+		cur.Reset()
+	}
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == ',' && !inRegex:
+			flush()
+			continue
+		case ch == '/' && !inRegex && strings.TrimSpace(cur.String()) == "":
+			inRegex = true
+		case ch == '/' && inRegex && s[i-1] != '\\':
+			if rest := strings.TrimSpace(s[i+1:]); rest == "" || rest[0] == ',' {
+				inRegex = false
+			}
+		}
+		cur.WriteByte(ch)
+	}
+	flush()
+	return
+}
+
+func newMatcher(pattern string) (matcher, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	pattern = strings.ToLower(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	return func(s string) bool {
+		ok, _ := path.Match(pattern, s)
+		return ok
+	}, nil
+}
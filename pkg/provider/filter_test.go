@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewFilter(t *testing.T) {
+	f, err := NewFilter(map[string]string{"name": "test"})
+	if err != nil || f != nil {
+		t.Errorf("got %v, %v without rules, want nil filter", f, err)
+	}
+	if _, err := NewFilter(map[string]string{"excludeRecordNames": "/[/"}); err == nil {
+		t.Error("want error for invalid regex")
+	}
+	if _, err := NewFilter(map[string]string{"includeDomains": "[a-"}); err == nil {
+		t.Error("want error for invalid glob")
+	}
+}
+
+func TestSplitPatterns(t *testing.T) {
+	for s, want := range map[string][]string{
+		"":                          nil,
+		"*.com, example.org,":       {"*.com", "example.org"},
+		"/^a{1,3}$/,b.com":          {"/^a{1,3}$/", "b.com"},
+		" /^(x|y),z$/ , /a\\/,b/,c": {"/^(x|y),z$/", "/a\\/,b/", "c"},
+		"a/b,c":                     {"a/b", "c"},
+	} {
+		if got := splitPatterns(s); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitPatterns(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestFilterDomains(t *testing.T) {
+	f, err := NewFilter(map[string]string{
+		"includeDomains": "*.com, example.org, /^[a-z]{1,2}\\.net$/",
+		"excludeDomains": "/^test\\./",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	domains := []Domain{
+		{DomainName: "example.com"},
+		{DomainName: "Example.ORG"},
+		{DomainName: "example.net"},
+		{DomainName: "test.com"},
+		{DomainName: "ab.net"},
+	}
+	var got []string
+	for _, d := range f.FilterDomains(domains) {
+		got = append(got, d.DomainName)
+	}
+	if want := []string{"example.com", "Example.ORG", "ab.net"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	var nilFilter *Filter
+	if got := nilFilter.FilterDomains(domains); len(got) != len(domains) {
+		t.Errorf("nil filter got %d domains, want %d", len(got), len(domains))
+	}
+}
+
+func TestFilterRecords(t *testing.T) {
+	f, err := NewFilter(map[string]string{
+		"excludeDomains":      "example.net",
+		"includeRecordTypes":  "A,CNAME,TXT",
+		"excludeRecordNames":  "/^_acme-challenge/",
+		"includeRecordStatus": "enable",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []Record{
+		{DomainName: "example.com", RecordType: "A", RecordName: "www", RecordStatus: "enable"},
+		{DomainName: "example.com", RecordType: "MX", RecordName: "@", RecordStatus: "enable"},
+		{DomainName: "example.com", RecordType: "TXT", RecordName: "_acme-challenge.www", RecordStatus: "enable"},
+		{DomainName: "example.com", RecordType: "CNAME", RecordName: "old", RecordStatus: "disable"},
+		{DomainName: "example.net", RecordType: "A", RecordName: "www", RecordStatus: "enable"},
+		{DomainName: "example.com", RecordType: "txt", RecordName: "@", RecordStatus: "ENABLE"},
+	}
+	var got []string
+	for _, r := range f.FilterRecords(records) {
+		got = append(got, r.RecordType+" "+r.RecordName)
+	}
+	if want := []string{"A www", "txt @"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
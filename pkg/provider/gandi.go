@@ -0,0 +1,239 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/golang-module/carbon/v2"
+)
+
+type GandiDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+const gandiPageSize = 100
+
+type gandiLiveDNSDomain struct {
+	Fqdn string `json:"fqdn"`
+}
+
+type gandiDomain struct {
+	ID        string   `json:"id"`
+	Fqdn      string   `json:"fqdn"`
+	Status    []string `json:"status"`
+	AutoRenew bool     `json:"autorenew"`
+	Dates     struct {
+		RegistryCreatedAt string `json:"registry_created_at"`
+		RegistryEndsAt    string `json:"registry_ends_at"`
+		UpdatedAt         string `json:"updated_at"`
+	} `json:"dates"`
+}
+
+type gandiRecord struct {
+	RrsetName   string   `json:"rrset_name"`
+	RrsetType   string   `json:"rrset_type"`
+	RrsetTTL    int      `json:"rrset_ttl"`
+	RrsetValues []string `json:"rrset_values"`
+}
+
+// NewGandiClient 初始化客户端，使用 Personal Access Token 认证
+func NewGandiClient(endpoint, token string) (*restapi.Client, error) {
+	return restapi.NewClient(endpoint, restapi.WithBearerToken(token))
+}
+
+// NewGandiDNS 创建 GandiDNS 实例
+func NewGandiDNS(account public.Account) (*GandiDNS, error) {
+	client, err := NewGandiClient(accountEndpoint(account, "https://api.gandi.net"), account.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &GandiDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (g *GandiDNS) ListDomains() ([]Domain, error) {
+	gd, err := NewGandiDNS(g.account)
+	if err != nil {
+		return nil, err
+	}
+	g.client = gd.client
+	var dataObj []Domain
+	domains, err := g.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	// 域名注册信息与 LiveDNS 托管的域名不一定在同一个账号下，获取失败不影响解析数据
+	domainNames, err := g.getDomainNameList()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ %s_%s ] get domain name list failed: %v", g.account.CloudProvider, g.account.CloudName, err))
+	}
+	for _, v := range domains {
+		d := g.getDomainDetail(domainNames, v.Fqdn)
+		d.CloudProvider = g.account.CloudProvider
+		d.CloudName = g.account.CloudName
+		d.DomainName = v.Fqdn
+		d.DomainStatus = "enable"
+		if d.DomainID == "" {
+			d.DomainID = v.Fqdn
+		}
+		dataObj = append(dataObj, d)
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (g *GandiDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	gd, err := NewGandiDNS(g.account)
+	if err != nil {
+		return nil, err
+	}
+	g.client = gd.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + g.account.CloudProvider + "_" + g.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]gandiRecord)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			<-ticker.C
+			records, err := g.getRecordList(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", g.account.CloudProvider, g.account.CloudName, err))
+			}
+			mu.Lock()
+			results[domain] = records
+			mu.Unlock()
+		}(domain.DomainName)
+	}
+	wg.Wait()
+	for domain, records := range results {
+		for _, v := range records {
+			// gandi 以 rrset 的形式返回记录，每个值拆分为一条记录
+			for _, value := range v.RrsetValues {
+				dataObj = append(dataObj, Record{
+					CloudProvider: g.account.CloudProvider,
+					CloudName:     g.account.CloudName,
+					DomainName:    domain,
+					RecordID:      recordID(domain, v.RrsetType, v.RrsetName, value),
+					RecordType:    v.RrsetType,
+					RecordName:    recordName(v.RrsetName),
+					RecordValue:   value,
+					RecordTTL:     strconv.Itoa(v.RrsetTTL),
+					RecordStatus:  "enable",
+					FullRecord:    fullRecord(v.RrsetName, domain),
+				})
+			}
+		}
+	}
+	return dataObj, nil
+}
+
+// https://api.gandi.net/docs/livedns/#get-v5-livedns-domains
+// getDomainList 获取 LiveDNS 托管的域名列表
+func (g *GandiDNS) getDomainList() (rst []gandiLiveDNSDomain, err error) {
+	page := 1
+	for {
+		var resp []gandiLiveDNSDomain
+		if err := g.client.Get("/v5/livedns/domains", gandiPage(page), &resp); err != nil {
+			return nil, err
+		}
+		rst = append(rst, resp...)
+		if len(resp) < gandiPageSize {
+			break
+		}
+		page++
+	}
+	return
+}
+
+// https://api.gandi.net/docs/domains/#get-v5-domain-domains
+// getDomainNameList 获取域名注册信息列表(与 LiveDNS 的域名列表注意区分)
+func (g *GandiDNS) getDomainNameList() (rst []gandiDomain, err error) {
+	page := 1
+	for {
+		var resp []gandiDomain
+		if err := g.client.Get("/v5/domain/domains", gandiPage(page), &resp); err != nil {
+			return nil, err
+		}
+		rst = append(rst, resp...)
+		if len(resp) < gandiPageSize {
+			break
+		}
+		page++
+	}
+	return
+}
+
+// https://api.gandi.net/docs/livedns/#get-v5-livedns-domains-fqdn-records
+// getRecordList 获取记录列表
+func (g *GandiDNS) getRecordList(domain string) (rst []gandiRecord, err error) {
+	page := 1
+	for {
+		var resp []gandiRecord
+		if err := g.client.Get("/v5/livedns/domains/"+domain+"/records", gandiPage(page), &resp); err != nil {
+			return rst, err
+		}
+		rst = append(rst, resp...)
+		if len(resp) < gandiPageSize {
+			break
+		}
+		page++
+	}
+	return
+}
+
+// getDomainDetail 获取域名的创建时间、到期时间、自动续费与转移锁状态
+func (g *GandiDNS) getDomainDetail(domainList []gandiDomain, domain string) (d Domain) {
+	for _, v := range domainList {
+		if v.Fqdn != domain {
+			continue
+		}
+		d.DomainID = v.ID
+		d.CreatedDate = v.Dates.RegistryCreatedAt
+		d.ExpiryDate = v.Dates.RegistryEndsAt
+		d.AutoRenew = strconv.FormatBool(v.AutoRenew)
+		d.Locked = "false"
+		for _, status := range v.Status {
+			if status == "clientTransferProhibited" {
+				d.Locked = "true"
+			}
+		}
+		if d.ExpiryDate != "" {
+			d.DaysUntilExpiry = carbon.Now().DiffInDays(carbon.Parse(d.ExpiryDate))
+		}
+	}
+	return
+}
+
+// gandiPage 分页参数
+func gandiPage(page int) url.Values {
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	params.Set("per_page", strconv.Itoa(gandiPageSize))
+	return params
+}
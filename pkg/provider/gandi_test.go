@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+)
+
+func TestGandiDNS(t *testing.T) {
+	setupCache(t)
+	srv := fixtureServer(t, func(r *http.Request) string {
+		if r.Header.Get("Authorization") != "Bearer pat" {
+			return "unauthorized"
+		}
+		return r.URL.Path
+	}, map[string]string{
+		"/v5/livedns/domains":                    "gandi/livedns.domains.json",
+		"/v5/domain/domains":                     "gandi/domain.domains.json",
+		"/v5/livedns/domains/example.fr/records": "gandi/livedns.records.json",
+	})
+	account := public.Account{
+		CloudProvider: public.GandiDnsProvider,
+		CloudName:     "test",
+		SecretKey:     "pat",
+		Endpoint:      srv.URL,
+	}
+	g := &GandiDNS{account: account}
+
+	domains, err := g.ListDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 1 {
+		t.Fatalf("got %d domains, want 1", len(domains))
+	}
+	if d := domains[0]; d.DomainID != "ba1167be-ffff-11e5-8add-00163e8fd4b8" || d.DomainName != "example.fr" ||
+		d.ExpiryDate != "2099-02-13T10:04:18Z" || d.Locked != "true" || d.AutoRenew != "true" {
+		t.Errorf("unexpected domain %+v", d)
+	}
+
+	cacheDomains(t, account, domains)
+	records, err := g.ListRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortRecords(records)
+	// rrset 的每个值拆分为一条记录
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	if r := records[0]; r.RecordName != "@" || r.FullRecord != "example.fr" || r.RecordValue != "192.0.2.1" || r.RecordTTL != "10800" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if records[0].RecordID == records[1].RecordID {
+		t.Errorf("records of the same rrset share id %s", records[0].RecordID)
+	}
+	if r := records[2]; r.FullRecord != "www.example.fr" || r.RecordType != "CNAME" {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
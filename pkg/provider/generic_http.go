@@ -0,0 +1,306 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/golang-module/carbon/v2"
+	"github.com/tidwall/gjson"
+)
+
+// GenericHTTPDNS 通过配置的映射关系从任意 HTTP/JSON 接口读取域名与记录，适用于小型提供商及内部 CMDB
+type GenericHTTPDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+// genericHTTPMaxPages 分页的最大页数，防止接口分页参数配置错误时无限请求
+const genericHTTPMaxPages = 1000
+
+// NewGenericHTTPClient 初始化客户端，authHeader 的格式为 "Header: Value"
+func NewGenericHTTPClient(account public.Account) (*restapi.Client, error) {
+	if account.Endpoint == "" {
+		return nil, errors.New("endpoint is required")
+	}
+	opts := []restapi.Option{restapi.WithJSONResponse()}
+	if authHeader := account.Options["authHeader"]; authHeader != "" {
+		key, value, ok := strings.Cut(authHeader, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid authHeader %q, want \"Header: Value\"", authHeader)
+		}
+		opts = append(opts, restapi.WithHeader(strings.TrimSpace(key), strings.TrimSpace(value)))
+	}
+	if account.Options["insecureSkipVerify"] == "true" {
+		opts = append(opts, restapi.WithInsecureSkipVerify())
+	}
+	return restapi.NewClient(accountEndpoint(account, ""), opts...)
+}
+
+// NewGenericHTTPDNS 创建 GenericHTTPDNS 实例
+func NewGenericHTTPDNS(account public.Account) (*GenericHTTPDNS, error) {
+	client, err := NewGenericHTTPClient(account)
+	if err != nil {
+		return nil, err
+	}
+	return &GenericHTTPDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (g *GenericHTTPDNS) ListDomains() ([]Domain, error) {
+	gd, err := NewGenericHTTPDNS(g.account)
+	if err != nil {
+		return nil, err
+	}
+	g.client = gd.client
+	mapping, err := parseGenericHTTPMapping(g.account.Options["domainsMapping"])
+	if err != nil {
+		return nil, err
+	}
+	items, err := g.getList(g.account.Options["domainsPath"], g.account.Options["domainsItems"])
+	if err != nil {
+		return nil, err
+	}
+	var dataObj []Domain
+	for _, item := range items {
+		d := Domain{}
+		applyGenericHTTPMapping(&d, item, mapping)
+		if d.DomainName == "" {
+			continue
+		}
+		d.CloudProvider = g.account.CloudProvider
+		d.CloudName = g.account.CloudName
+		if d.DomainID == "" {
+			d.DomainID = d.DomainName
+		}
+		d.DomainStatus = foldStatus(d.DomainStatus)
+		if d.DomainStatus == "" {
+			d.DomainStatus = "enable"
+		}
+		if d.ExpiryDate != "" && d.DaysUntilExpiry == 0 {
+			d.DaysUntilExpiry = carbon.Now().DiffInDays(carbon.Parse(d.ExpiryDate))
+		}
+		dataObj = append(dataObj, d)
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (g *GenericHTTPDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	gd, err := NewGenericHTTPDNS(g.account)
+	if err != nil {
+		return nil, err
+	}
+	g.client = gd.client
+	mapping, err := parseGenericHTTPMapping(g.account.Options["recordsMapping"])
+	if err != nil {
+		return nil, err
+	}
+	rst, err := public.Cache.Get(public.DomainList + "_" + g.account.CloudProvider + "_" + g.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]gjson.Result)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domainName, domainId string) {
+			defer wg.Done()
+			<-ticker.C
+			path := strings.NewReplacer("{domain_id}", url.PathEscape(domainId), "{domain_name}", url.PathEscape(domainName)).
+				Replace(g.account.Options["recordsPath"])
+			items, err := g.getList(path, g.account.Options["recordsItems"])
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", g.account.CloudProvider, g.account.CloudName, err))
+			}
+			mu.Lock()
+			results[domainName] = items
+			mu.Unlock()
+		}(domain.DomainName, domain.DomainID)
+	}
+	wg.Wait()
+	for domain, items := range results {
+		for _, item := range items {
+			r := Record{}
+			applyGenericHTTPMapping(&r, item, mapping)
+			r.CloudProvider = g.account.CloudProvider
+			r.CloudName = g.account.CloudName
+			if r.DomainName == "" {
+				r.DomainName = domain
+			}
+			if r.FullRecord == "" {
+				r.FullRecord = fullRecord(r.RecordName, r.DomainName)
+			}
+			r.RecordName = recordName(r.RecordName)
+			if r.RecordID == "" {
+				r.RecordID = recordID(r.DomainName, r.RecordType, r.RecordName, r.RecordValue)
+			}
+			r.RecordStatus = foldStatus(r.RecordStatus)
+			if r.RecordStatus == "" {
+				r.RecordStatus = "enable"
+			}
+			dataObj = append(dataObj, r)
+		}
+	}
+	return dataObj, nil
+}
+
+// getList 按配置的分页方式读取列表，itemsPath 为列表在响应中的 gjson 路径，为空时响应本身即为列表
+func (g *GenericHTTPDNS) getList(path, itemsPath string) (rst []gjson.Result, err error) {
+	opts := g.account.Options
+	pagination := opts["pagination"]
+	pageSize, _ := strconv.Atoi(opts["pageSize"])
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	page, _ := strconv.Atoi(opts["pageStart"])
+	if opts["pageStart"] == "" {
+		page = 1
+	}
+	offset := 0
+	cursor := ""
+	for i := 0; i < genericHTTPMaxPages; i++ {
+		params := url.Values{}
+		switch pagination {
+		case "", "none":
+		case "page":
+			params.Set(genericHTTPOption(opts, "pageParam", "page"), strconv.Itoa(page))
+			params.Set(genericHTTPOption(opts, "pageSizeParam", "per_page"), strconv.Itoa(pageSize))
+		case "offset":
+			params.Set(genericHTTPOption(opts, "offsetParam", "offset"), strconv.Itoa(offset))
+			params.Set(genericHTTPOption(opts, "limitParam", "limit"), strconv.Itoa(pageSize))
+		case "cursor":
+			// 游标为完整的下一页地址时(如 links.next)直接请求该地址
+			if strings.HasPrefix(cursor, "http://") || strings.HasPrefix(cursor, "https://") || strings.HasPrefix(cursor, "/") {
+				path = cursor
+				break
+			}
+			if cursor != "" {
+				params.Set(genericHTTPOption(opts, "cursorParam", "cursor"), cursor)
+			}
+			if opts["pageSizeParam"] != "" {
+				params.Set(opts["pageSizeParam"], strconv.Itoa(pageSize))
+			}
+		default:
+			return nil, fmt.Errorf("unsupported pagination %q", pagination)
+		}
+		var resp json.RawMessage
+		if err := g.client.Get(path, params, &resp); err != nil {
+			return rst, err
+		}
+		body := gjson.ParseBytes(resp)
+		items := body
+		if itemsPath != "" {
+			items = body.Get(itemsPath)
+		}
+		batch := items.Array()
+		rst = append(rst, batch...)
+
+		switch pagination {
+		case "page", "offset":
+			// 配置了 totalPath 时以总数为准，否则以返回数量不足一页作为结束
+			if totalPath := opts["totalPath"]; totalPath != "" {
+				if len(batch) == 0 || int64(len(rst)) >= body.Get(totalPath).Int() {
+					return rst, nil
+				}
+			} else if len(batch) < pageSize {
+				return rst, nil
+			}
+			page++
+			offset += len(batch)
+		case "cursor":
+			cursor = body.Get(genericHTTPOption(opts, "cursorPath", "next")).String()
+			if cursor == "" || len(batch) == 0 {
+				return rst, nil
+			}
+		default:
+			return rst, nil
+		}
+	}
+	return rst, fmt.Errorf("%s exceeded %d pages", path, genericHTTPMaxPages)
+}
+
+// genericHTTPMapping 字段名到 gjson 路径的映射，字段名为 Domain/Record 的 json 标签，labels.xxx 映射到 Labels
+type genericHTTPMapping map[string]string
+
+// parseGenericHTTPMapping 解析 "domain_name=name,domain_id=id,labels.owner=owner.name" 形式的映射
+func parseGenericHTTPMapping(s string) (genericHTTPMapping, error) {
+	mapping := make(genericHTTPMapping)
+	for _, item := range splitList(s) {
+		field, path, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(field) == "" || strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("invalid mapping %q, want field=path", item)
+		}
+		mapping[strings.TrimSpace(field)] = strings.TrimSpace(path)
+	}
+	if len(mapping) == 0 {
+		return nil, errors.New("mapping is empty")
+	}
+	return mapping, nil
+}
+
+// applyGenericHTTPMapping 按 json 标签将 item 中的值写入 obj 对应的字段
+func applyGenericHTTPMapping(obj interface{}, item gjson.Result, mapping genericHTTPMapping) {
+	v := reflect.ValueOf(obj).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		field := v.Field(i)
+		if tag == "labels" {
+			for name, path := range mapping {
+				label, ok := strings.CutPrefix(name, "labels.")
+				if !ok {
+					continue
+				}
+				if value := item.Get(path); value.Exists() {
+					if field.IsNil() {
+						field.Set(reflect.ValueOf(map[string]string{}))
+					}
+					field.SetMapIndex(reflect.ValueOf(label), reflect.ValueOf(value.String()))
+				}
+			}
+			continue
+		}
+		path, ok := mapping[tag]
+		if !ok {
+			continue
+		}
+		value := item.Get(path)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value.String())
+		case reflect.Int64:
+			field.SetInt(value.Int())
+		}
+	}
+}
+
+func genericHTTPOption(options map[string]string, key, defaultValue string) string {
+	if v := options[key]; v != "" {
+		return v
+	}
+	return defaultValue
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,18 +20,37 @@ type GodaddyDNS struct {
 	client  *daddy.Client
 }
 
-// NewGodaddyClient 初始化客户端
-func NewGodaddyClient(secretID, secretKey string) (*daddy.Client, error) {
-	client, err := daddy.NewClient(secretID, secretKey, false)
+const (
+	godaddyProductionEndpoint = "https://api.godaddy.com"
+	godaddyOTEEndpoint        = "https://api.ote-godaddy.com"
+	// godaddyDomainPageSize 域名列表每页数量，接口上限为 1000
+	godaddyDomainPageSize = 1000
+	// godaddyRecordPageSize 记录列表每页数量
+	godaddyRecordPageSize = 500
+)
+
+// NewGodaddyClient 初始化客户端，environment 为 ote 时使用测试环境，配置了 endpoint 时以其为准
+// 配置了 shopperId 时以经销商身份查询该客户名下的域名
+func NewGodaddyClient(account public.Account) (*daddy.Client, error) {
+	defaultEndpoint := godaddyProductionEndpoint
+	switch strings.ToLower(account.Options["environment"]) {
+	case "", "production", "prod":
+	case "ote", "test":
+		defaultEndpoint = godaddyOTEEndpoint
+	default:
+		return nil, fmt.Errorf("unsupported godaddy environment %q", account.Options["environment"])
+	}
+	client, err := daddy.NewClientWithURL(account.SecretID, account.SecretKey, accountEndpoint(account, defaultEndpoint))
 	if err != nil {
 		return nil, err
 	}
+	client.Shopper = account.Options["shopperId"]
 	return client, nil
 }
 
 // NewGodaddyDNS 创建 GodaddyDNS 实例
 func NewGodaddyDNS(account public.Account) (*GodaddyDNS, error) {
-	client, err := NewGodaddyClient(account.SecretID, account.SecretKey)
+	client, err := NewGodaddyClient(account)
 	if err != nil {
 		return nil, err
 	}
@@ -42,12 +62,7 @@ func NewGodaddyDNS(account public.Account) (*GodaddyDNS, error) {
 
 // ListDomains 获取域名列表
 func (g *GodaddyDNS) ListDomains() ([]Domain, error) {
-	gd, err := NewGodaddyDNS(public.Account{
-		CloudProvider: g.account.CloudProvider,
-		CloudName:     g.account.CloudName,
-		SecretID:      g.account.SecretID,
-		SecretKey:     g.account.SecretKey,
-	})
+	gd, err := NewGodaddyDNS(g.account)
 	if err != nil {
 		return nil, err
 	}
@@ -58,6 +73,10 @@ func (g *GodaddyDNS) ListDomains() ([]Domain, error) {
 		return nil, err
 	}
 	for _, v := range domains {
+		var labels map[string]string
+		if shopperID := g.account.Options["shopperId"]; shopperID != "" {
+			labels = map[string]string{"shopper_id": shopperID}
+		}
 		dataObj = append(dataObj, Domain{
 			CloudProvider:   g.account.CloudProvider,
 			CloudName:       g.account.CloudName,
@@ -68,6 +87,7 @@ func (g *GodaddyDNS) ListDomains() ([]Domain, error) {
 			CreatedDate:     v.CreatedAt,
 			ExpiryDate:      v.Expires,
 			DaysUntilExpiry: carbon.Now().DiffInDays(carbon.Parse(v.Expires)),
+			Labels:          labels,
 		})
 	}
 	return dataObj, nil
@@ -81,12 +101,7 @@ func (g *GodaddyDNS) ListRecords() ([]Record, error) {
 		wg      sync.WaitGroup
 		mu      sync.Mutex
 	)
-	tcd, err := NewGodaddyDNS(public.Account{
-		CloudProvider: g.account.CloudProvider,
-		CloudName:     g.account.CloudName,
-		SecretID:      g.account.SecretID,
-		SecretKey:     g.account.SecretKey,
-	})
+	tcd, err := NewGodaddyDNS(g.account)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +141,7 @@ func (g *GodaddyDNS) ListRecords() ([]Record, error) {
 				CloudProvider: g.account.CloudProvider,
 				CloudName:     g.account.CloudName,
 				DomainName:    domain,
-				RecordID:      public.GetID(),
+				RecordID:      recordID(domain, v.Type, v.Name, v.Data),
 				RecordType:    v.Type,
 				RecordName:    v.Name,
 				RecordValue:   v.Data,
@@ -134,31 +149,45 @@ func (g *GodaddyDNS) ListRecords() ([]Record, error) {
 				RecordWeight:  strconv.Itoa(v.Weight),
 				RecordStatus:  "enable",
 				RecordRemark:  v.Name,
-				FullRecord:    v.Name + "." + domain,
+				FullRecord:    fullRecord(v.Name, domain),
 			})
 		}
 	}
 	return dataObj, nil
 }
 
-// https://developer.godaddy.com/doc/endpoint/domains
-// GetDomainList 获取云解析中域名列表
-func (g *GodaddyDNS) getDomainList() ([]daddy.DomainSummary, error) {
-	domains, err := g.client.Domains.List(nil, nil, 0, "", nil, "")
-	if err != nil {
-		return nil, err
+// https://developer.godaddy.com/doc/endpoint/domains#/v1/list
+// GetDomainList 获取云解析中域名列表，以上一页最后一个域名作为 marker 翻页
+func (g *GodaddyDNS) getDomainList() (rst []daddy.DomainSummary, err error) {
+	marker := ""
+	for {
+		domains, err := g.client.Domains.List(nil, nil, godaddyDomainPageSize, marker, nil, "")
+		if err != nil {
+			return rst, err
+		}
+		rst = append(rst, domains...)
+		if len(domains) < godaddyDomainPageSize {
+			break
+		}
+		marker = domains[len(domains)-1].Domain
 	}
-
-	return domains, nil
+	return rst, nil
 }
 
-// https://developer.godaddy.com/doc/endpoint/domains
+// https://developer.godaddy.com/doc/endpoint/domains#/v1/recordGet
 // RecordList 域名记录列表
-func (g *GodaddyDNS) getRecordList(domain string) ([]daddy.DNSRecord, error) {
-	// TODO 目前写死的获取500条记录
-	rds, err := g.client.Domains.GetRecords(domain, "", "", 0, 500)
-	if err != nil {
-		fmt.Printf("Error listing records: %v\n", err)
+func (g *GodaddyDNS) getRecordList(domain string) (rst []daddy.DNSRecord, err error) {
+	offset := 0
+	for {
+		rds, err := g.client.Domains.GetRecords(domain, "", "", offset, godaddyRecordPageSize)
+		if err != nil {
+			return rst, err
+		}
+		rst = append(rst, rds...)
+		if len(rds) < godaddyRecordPageSize {
+			break
+		}
+		offset += len(rds)
 	}
-	return rds, err
+	return rst, nil
 }
@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+type HetznerDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+type hetznerMeta struct {
+	Pagination struct {
+		Page         int `json:"page"`
+		PerPage      int `json:"per_page"`
+		LastPage     int `json:"last_page"`
+		TotalEntries int `json:"total_entries"`
+	} `json:"pagination"`
+}
+
+type hetznerZone struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	TTL      int    `json:"ttl"`
+	Status   string `json:"status"`
+	Created  string `json:"created"`
+	Modified string `json:"modified"`
+}
+
+type hetznerRecord struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	TTL      int    `json:"ttl"`
+	ZoneID   string `json:"zone_id"`
+	Modified string `json:"modified"`
+}
+
+// NewHetznerClient 初始化客户端
+func NewHetznerClient(token string) (*restapi.Client, error) {
+	return restapi.NewClient("https://dns.hetzner.com/api", restapi.WithHeader("Auth-API-Token", token))
+}
+
+// NewHetznerDNS 创建 HetznerDNS 实例
+func NewHetznerDNS(account public.Account) (*HetznerDNS, error) {
+	client, err := NewHetznerClient(account.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &HetznerDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (h *HetznerDNS) ListDomains() ([]Domain, error) {
+	hd, err := NewHetznerDNS(h.account)
+	if err != nil {
+		return nil, err
+	}
+	h.client = hd.client
+	var dataObj []Domain
+	zones, err := h.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range zones {
+		dataObj = append(dataObj, Domain{
+			CloudProvider: h.account.CloudProvider,
+			CloudName:     h.account.CloudName,
+			DomainID:      v.ID,
+			DomainName:    v.Name,
+			DomainStatus:  foldStatus(v.Status),
+			CreatedDate:   v.Created,
+		})
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (h *HetznerDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	hd, err := NewHetznerDNS(h.account)
+	if err != nil {
+		return nil, err
+	}
+	h.client = hd.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + h.account.CloudProvider + "_" + h.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]hetznerRecord)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domainName, domainId string) {
+			defer wg.Done()
+			<-ticker.C
+			records, err := h.getRecordList(domainId)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", h.account.CloudProvider, h.account.CloudName, err))
+			}
+			mu.Lock()
+			results[domainName] = records
+			mu.Unlock()
+		}(domain.DomainName, domain.DomainID)
+	}
+	wg.Wait()
+	for domain, records := range results {
+		for _, v := range records {
+			dataObj = append(dataObj, Record{
+				CloudProvider: h.account.CloudProvider,
+				CloudName:     h.account.CloudName,
+				DomainName:    domain,
+				RecordID:      v.ID,
+				RecordType:    v.Type,
+				RecordName:    recordName(v.Name),
+				RecordValue:   v.Value,
+				RecordTTL:     strconv.Itoa(v.TTL),
+				RecordStatus:  "enable",
+				UpdateTime:    v.Modified,
+				FullRecord:    fullRecord(v.Name, domain),
+			})
+		}
+	}
+	return dataObj, nil
+}
+
+// https://dns.hetzner.com/api-docs#operation/GetZones
+// getDomainList 获取域名列表
+func (h *HetznerDNS) getDomainList() (rst []hetznerZone, err error) {
+	page := 1
+	for {
+		var resp struct {
+			Zones []hetznerZone `json:"zones"`
+			Meta  hetznerMeta   `json:"meta"`
+		}
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("per_page", "100")
+		if err := h.client.Get("/v1/zones", params, &resp); err != nil {
+			return nil, err
+		}
+		rst = append(rst, resp.Zones...)
+		if page >= resp.Meta.Pagination.LastPage {
+			break
+		}
+		page++
+	}
+	return
+}
+
+// https://dns.hetzner.com/api-docs#operation/GetRecords
+// getRecordList 获取记录列表
+func (h *HetznerDNS) getRecordList(zoneId string) (rst []hetznerRecord, err error) {
+	page := 1
+	for {
+		var resp struct {
+			Records []hetznerRecord `json:"records"`
+			Meta    hetznerMeta     `json:"meta"`
+		}
+		params := url.Values{}
+		params.Set("zone_id", zoneId)
+		params.Set("page", strconv.Itoa(page))
+		params.Set("per_page", "100")
+		if err := h.client.Get("/v1/records", params, &resp); err != nil {
+			return rst, err
+		}
+		rst = append(rst, resp.Records...)
+		if page >= resp.Meta.Pagination.LastPage {
+			break
+		}
+		page++
+	}
+	return
+}
@@ -0,0 +1,301 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+// InfobloxDNS 通过 Infoblox WAPI 获取权威域及记录，支持多个 DNS 视图
+type InfobloxDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+// infobloxExtAttrs 扩展属性，格式为 {"Owner": {"value": "team-a"}}
+type infobloxExtAttrs map[string]struct {
+	Value interface{} `json:"value"`
+}
+
+type infobloxZone struct {
+	Ref        string           `json:"_ref"`
+	Fqdn       string           `json:"fqdn"`
+	View       string           `json:"view"`
+	Comment    string           `json:"comment"`
+	Disable    bool             `json:"disable"`
+	ZoneFormat string           `json:"zone_format"`
+	ExtAttrs   infobloxExtAttrs `json:"extattrs"`
+}
+
+// infobloxRecordTypes 需要获取的记录类型，以及记录值对应的字段
+var infobloxRecordTypes = []struct {
+	object     string
+	recordType string
+	fields     []string
+}{
+	{"record:a", "A", []string{"ipv4addr"}},
+	{"record:aaaa", "AAAA", []string{"ipv6addr"}},
+	{"record:cname", "CNAME", []string{"canonical"}},
+	{"record:mx", "MX", []string{"preference", "mail_exchanger"}},
+	{"record:txt", "TXT", []string{"text"}},
+	{"record:ptr", "PTR", []string{"ptrdname"}},
+	{"record:srv", "SRV", []string{"priority", "weight", "port", "target"}},
+	{"record:caa", "CAA", []string{"ca_flag", "ca_tag", "ca_value"}},
+}
+
+// NewInfobloxClient 初始化客户端，endpoint 需包含 WAPI 版本，如 https://gm.example.com/wapi/v2.12
+func NewInfobloxClient(endpoint, username, password string, insecure bool) (*restapi.Client, error) {
+	options := []restapi.Option{restapi.WithBasicAuth(username, password)}
+	if insecure {
+		options = append(options, restapi.WithInsecureSkipVerify())
+	}
+	return restapi.NewClient(strings.TrimSuffix(endpoint, "/"), options...)
+}
+
+// NewInfobloxDNS 创建 InfobloxDNS 实例
+func NewInfobloxDNS(account public.Account) (*InfobloxDNS, error) {
+	if account.Endpoint == "" {
+		return nil, errors.New("missing infoblox endpoint")
+	}
+	client, err := NewInfobloxClient(account.Endpoint, account.SecretID, account.SecretKey, account.Options["insecureSkipVerify"] == "true")
+	if err != nil {
+		return nil, err
+	}
+	return &InfobloxDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表，同名的域在不同视图中会分别返回
+func (i *InfobloxDNS) ListDomains() ([]Domain, error) {
+	ibd, err := NewInfobloxDNS(i.account)
+	if err != nil {
+		return nil, err
+	}
+	i.client = ibd.client
+	var dataObj []Domain
+	views := splitList(i.account.Options["views"])
+	if len(views) == 0 {
+		views = []string{""}
+	}
+	for _, view := range views {
+		zones, err := i.getDomainList(view)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range zones {
+			status := "enable"
+			if v.Disable {
+				status = "disable"
+			}
+			labels := v.ExtAttrs.labels()
+			labels["view"] = v.View
+			labels["zone_format"] = v.ZoneFormat
+			// 同名的域可能存在于多个视图中，域ID需包含视图
+			domainID := v.Ref
+			if domainID == "" {
+				domainID = v.View + "/" + v.Fqdn
+			}
+			dataObj = append(dataObj, Domain{
+				CloudProvider: i.account.CloudProvider,
+				CloudName:     i.account.CloudName,
+				DomainID:      domainID,
+				DomainName:    v.Fqdn,
+				DomainRemark:  v.Comment,
+				DomainStatus:  status,
+				Labels:        labels,
+			})
+		}
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (i *InfobloxDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	ibd, err := NewInfobloxDNS(i.account)
+	if err != nil {
+		return nil, err
+	}
+	i.client = ibd.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + i.account.CloudProvider + "_" + i.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	// 限制并发，避免对 Grid Master 造成压力
+	semaphore := make(chan struct{}, 5)
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domain Domain) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			records, err := i.getRecordList(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", i.account.CloudProvider, i.account.CloudName, err))
+			}
+			mu.Lock()
+			dataObj = append(dataObj, records...)
+			mu.Unlock()
+		}(domain)
+	}
+	wg.Wait()
+	return dataObj, nil
+}
+
+// https://ipam.illinois.edu/wapidoc/objects/zone_auth.html
+// getDomainList 获取权威域列表，view 为空时返回全部视图
+func (i *InfobloxDNS) getDomainList(view string) (rst []infobloxZone, err error) {
+	params := url.Values{}
+	params.Set("_return_fields", "fqdn,view,comment,disable,zone_format,extattrs")
+	if view != "" {
+		params.Set("view", view)
+	}
+	err = i.paging("/zone_auth", params, func(data json.RawMessage) error {
+		var zones []infobloxZone
+		if err := json.Unmarshal(data, &zones); err != nil {
+			return err
+		}
+		rst = append(rst, zones...)
+		return nil
+	})
+	return
+}
+
+// getRecordList 按记录类型依次获取域中的记录
+func (i *InfobloxDNS) getRecordList(domain Domain) (rst []Record, err error) {
+	for _, t := range infobloxRecordTypes {
+		params := url.Values{}
+		params.Set("zone", domain.DomainName)
+		params.Set("view", domain.Labels["view"])
+		params.Set("_return_fields+", "name,view,zone,ttl,comment,disable,extattrs,"+strings.Join(t.fields, ","))
+		err := i.paging("/"+t.object, params, func(data json.RawMessage) error {
+			var items []map[string]json.RawMessage
+			if err := json.Unmarshal(data, &items); err != nil {
+				return err
+			}
+			for _, item := range items {
+				rst = append(rst, i.toRecord(domain, t.recordType, t.fields, item))
+			}
+			return nil
+		})
+		if err != nil {
+			return rst, fmt.Errorf("get %s of %s failed: %w", t.object, domain.DomainName, err)
+		}
+	}
+	return rst, nil
+}
+
+// toRecord 将 WAPI 返回的记录对象转换为 Record，扩展属性写入 Labels
+func (i *InfobloxDNS) toRecord(domain Domain, recordType string, fields []string, item map[string]json.RawMessage) Record {
+	var (
+		ref, name, comment string
+		ttl                json.Number
+		disable            bool
+		extAttrs           infobloxExtAttrs
+		values             []string
+	)
+	_ = json.Unmarshal(item["_ref"], &ref)
+	_ = json.Unmarshal(item["name"], &name)
+	_ = json.Unmarshal(item["comment"], &comment)
+	_ = json.Unmarshal(item["ttl"], &ttl)
+	_ = json.Unmarshal(item["disable"], &disable)
+	_ = json.Unmarshal(item["extattrs"], &extAttrs)
+	for _, field := range fields {
+		var value interface{}
+		_ = json.Unmarshal(item[field], &value)
+		values = append(values, fmt.Sprint(value))
+	}
+	status := "enable"
+	if disable {
+		status = "disable"
+	}
+	rr := name
+	if rr == domain.DomainName {
+		rr = "@"
+	} else {
+		rr = strings.TrimSuffix(rr, "."+domain.DomainName)
+	}
+	labels := extAttrs.labels()
+	labels["view"] = domain.Labels["view"]
+	value := strings.Join(values, " ")
+	// WAPI 的 _ref 已包含视图，未返回时按视图与记录内容生成记录ID，避免不同视图中的同名记录重复
+	if ref == "" {
+		ref = recordID(domain.Labels["view"]+"/"+domain.DomainName, recordType, rr, value)
+	}
+	return Record{
+		CloudProvider: i.account.CloudProvider,
+		CloudName:     i.account.CloudName,
+		DomainName:    domain.DomainName,
+		RecordID:      ref,
+		RecordType:    recordType,
+		RecordName:    rr,
+		RecordValue:   value,
+		RecordTTL:     ttl.String(),
+		RecordStatus:  status,
+		RecordRemark:  comment,
+		FullRecord:    name,
+		Labels:        labels,
+	}
+}
+
+// paging 使用 WAPI 的分页参数获取全部结果
+func (i *InfobloxDNS) paging(path string, params url.Values, handle func(json.RawMessage) error) error {
+	params.Set("_paging", "1")
+	params.Set("_return_as_object", "1")
+	params.Set("_max_results", "1000")
+	for {
+		var resp struct {
+			Result     json.RawMessage `json:"result"`
+			NextPageID string          `json:"next_page_id"`
+		}
+		if err := i.client.Get(path, params, &resp); err != nil {
+			return err
+		}
+		if err := handle(resp.Result); err != nil {
+			return err
+		}
+		if resp.NextPageID == "" {
+			return nil
+		}
+		params = url.Values{}
+		params.Set("_page_id", resp.NextPageID)
+	}
+}
+
+// labels 将扩展属性转换为标签，多值属性以逗号拼接
+func (e infobloxExtAttrs) labels() map[string]string {
+	labels := make(map[string]string)
+	for k, v := range e {
+		switch value := v.Value.(type) {
+		case []interface{}:
+			var values []string
+			for _, item := range value {
+				values = append(values, fmt.Sprint(item))
+			}
+			sort.Strings(values)
+			labels[k] = strings.Join(values, ",")
+		default:
+			labels[k] = fmt.Sprint(value)
+		}
+	}
+	return labels
+}
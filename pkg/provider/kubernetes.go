@@ -0,0 +1,424 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/weppos/publicsuffix-go/publicsuffix"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// KubernetesDNS 将 Kubernetes 中的 Ingress、Gateway、HTTPRoute 以及 ExternalDNS 的 DNSEndpoint 作为记录来源
+type KubernetesDNS struct {
+	account  public.Account
+	clusters []KubernetesCluster
+	records  []Record // ListDomains 读取的记录，供随后的 ListRecords 复用，避免每轮读取两次集群
+	listed   bool
+}
+
+// KubernetesCluster 一个集群的客户端，Dynamic 用于读取 Gateway API 与 ExternalDNS 的 CRD
+type KubernetesCluster struct {
+	Name    string
+	Client  kubernetes.Interface
+	Dynamic dynamic.Interface
+}
+
+var (
+	gatewayResource     = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+	httpRouteResource   = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+	dnsEndpointResource = schema.GroupVersionResource{Group: "externaldns.k8s.io", Version: "v1alpha1", Resource: "dnsendpoints"}
+)
+
+// NewKubernetesDNS 创建 KubernetesDNS 实例，kubeconfigs 为空时使用 in-cluster 配置
+func NewKubernetesDNS(account public.Account) (*KubernetesDNS, error) {
+	var clusters []KubernetesCluster
+	kubeconfigs := splitList(account.Options["kubeconfigs"])
+	if len(kubeconfigs) == 0 {
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, err
+		}
+		cluster, err := newKubernetesCluster("in-cluster", config)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, cluster)
+	}
+	for _, kubeconfig := range kubeconfigs {
+		// 支持 path:context 的形式指定上下文
+		path, kubeContext := kubeconfig, ""
+		if i := strings.LastIndex(kubeconfig, ":"); i > 1 && !strings.ContainsAny(kubeconfig[i+1:], `/\`) {
+			path, kubeContext = kubeconfig[:i], kubeconfig[i+1:]
+		}
+		config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: path},
+			&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+		).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("load kubeconfig %s failed: %w", kubeconfig, err)
+		}
+		name := kubeContext
+		if name == "" {
+			name = path
+		}
+		cluster, err := newKubernetesCluster(name, config)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, cluster)
+	}
+	return NewKubernetesDNSWithClusters(account, clusters), nil
+}
+
+// NewKubernetesDNSWithClusters 使用已创建的集群客户端创建实例，可传入 fake clientset
+func NewKubernetesDNSWithClusters(account public.Account, clusters []KubernetesCluster) *KubernetesDNS {
+	return &KubernetesDNS{
+		account:  account,
+		clusters: clusters,
+	}
+}
+
+func newKubernetesCluster(name string, config *rest.Config) (KubernetesCluster, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return KubernetesCluster{}, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return KubernetesCluster{}, err
+	}
+	return KubernetesCluster{Name: name, Client: client, Dynamic: dynamicClient}, nil
+}
+
+// ListDomains 获取域名列表，由记录的主机名推断所属域名
+func (k *KubernetesDNS) ListDomains() ([]Domain, error) {
+	records, err := k.listRecords()
+	if err != nil {
+		return nil, err
+	}
+	k.records, k.listed = records, true
+	seen := make(map[string]bool)
+	var dataObj []Domain
+	for _, v := range records {
+		if seen[v.DomainName] {
+			continue
+		}
+		seen[v.DomainName] = true
+		dataObj = append(dataObj, Domain{
+			CloudProvider: k.account.CloudProvider,
+			CloudName:     k.account.CloudName,
+			DomainID:      v.DomainName,
+			DomainName:    v.DomainName,
+			DomainStatus:  "enable",
+		})
+	}
+	sort.Slice(dataObj, func(i, j int) bool { return dataObj[i].DomainName < dataObj[j].DomainName })
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表，优先使用 ListDomains 已读取的结果
+func (k *KubernetesDNS) ListRecords() ([]Record, error) {
+	if k.listed {
+		records := k.records
+		k.records, k.listed = nil, false
+		return records, nil
+	}
+	return k.listRecords()
+}
+
+// listRecords 读取所有集群的记录，同一对象的同一主机名与目标只保留一条
+func (k *KubernetesDNS) listRecords() ([]Record, error) {
+	if len(k.clusters) == 0 {
+		kd, err := NewKubernetesDNS(k.account)
+		if err != nil {
+			return nil, err
+		}
+		k.clusters = kd.clusters
+	}
+	if len(k.clusters) == 0 {
+		return nil, errors.New("no kubernetes cluster configured")
+	}
+	var (
+		dataObj []Record
+		seen    = make(map[string]bool)
+	)
+	for _, cluster := range k.clusters {
+		var records []Record
+		records = append(records, k.listIngressRecords(cluster)...)
+		records = append(records, k.listGatewayRecords(cluster)...)
+		records = append(records, k.listDNSEndpointRecords(cluster)...)
+		for _, v := range records {
+			if seen[v.RecordID] {
+				continue
+			}
+			seen[v.RecordID] = true
+			dataObj = append(dataObj, v)
+		}
+	}
+	return dataObj, nil
+}
+
+// listIngressRecords 读取 Ingress 的 rules 与 tls 中的主机名，记录值为负载均衡的地址
+func (k *KubernetesDNS) listIngressRecords(cluster KubernetesCluster) (rst []Record) {
+	ingresses, err := cluster.Client.NetworkingV1().Ingresses(k.namespace()).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ %s_%s ] list ingresses in %s failed: %v", k.account.CloudProvider, k.account.CloudName, cluster.Name, err))
+		return
+	}
+	for _, ing := range ingresses.Items {
+		hosts := make(map[string]bool)
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host != "" {
+				hosts[rule.Host] = true
+			}
+		}
+		for _, tls := range ing.Spec.TLS {
+			for _, host := range tls.Hosts {
+				hosts[host] = true
+			}
+		}
+		var targets []string
+		for _, lb := range ing.Status.LoadBalancer.Ingress {
+			if lb.IP != "" {
+				targets = append(targets, lb.IP)
+			}
+			if lb.Hostname != "" {
+				targets = append(targets, lb.Hostname)
+			}
+		}
+		labels := map[string]string{
+			"cluster":   cluster.Name,
+			"namespace": ing.Namespace,
+			"kind":      "Ingress",
+			"object":    ing.Name,
+		}
+		if ing.Spec.IngressClassName != nil {
+			labels["ingress_class"] = *ing.Spec.IngressClassName
+		}
+		for host := range hosts {
+			rst = append(rst, k.toRecords(host, "", targets, 0, labels)...)
+		}
+	}
+	return
+}
+
+// listGatewayRecords 读取 Gateway 的 listener 与 HTTPRoute 的主机名，记录值为所属 Gateway 的地址
+func (k *KubernetesDNS) listGatewayRecords(cluster KubernetesCluster) (rst []Record) {
+	gateways, err := cluster.Dynamic.Resource(gatewayResource).Namespace(k.namespace()).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		// 集群未安装 Gateway API 时忽略
+		logger.Debug(fmt.Sprintf("[ %s_%s ] list gateways in %s failed: %v", k.account.CloudProvider, k.account.CloudName, cluster.Name, err))
+		return
+	}
+	addresses := make(map[string][]string)
+	for _, gw := range gateways.Items {
+		var targets []string
+		items, _, _ := unstructured.NestedSlice(gw.Object, "status", "addresses")
+		for _, item := range items {
+			if address, ok := item.(map[string]interface{}); ok {
+				if value, ok := address["value"].(string); ok {
+					targets = append(targets, value)
+				}
+			}
+		}
+		addresses[gw.GetNamespace()+"/"+gw.GetName()] = targets
+		labels := map[string]string{
+			"cluster":   cluster.Name,
+			"namespace": gw.GetNamespace(),
+			"kind":      "Gateway",
+			"object":    gw.GetName(),
+		}
+		listeners, _, _ := unstructured.NestedSlice(gw.Object, "spec", "listeners")
+		hosts := make(map[string]bool)
+		for _, item := range listeners {
+			if listener, ok := item.(map[string]interface{}); ok {
+				if host, ok := listener["hostname"].(string); ok && host != "" {
+					hosts[host] = true
+				}
+			}
+		}
+		for host := range hosts {
+			rst = append(rst, k.toRecords(host, "", targets, 0, labels)...)
+		}
+	}
+
+	routes, err := cluster.Dynamic.Resource(httpRouteResource).Namespace(k.namespace()).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		logger.Debug(fmt.Sprintf("[ %s_%s ] list httproutes in %s failed: %v", k.account.CloudProvider, k.account.CloudName, cluster.Name, err))
+		return
+	}
+	for _, route := range routes.Items {
+		var (
+			targets  []string
+			gateways []string
+		)
+		// 同一 Gateway 可通过不同的 sectionName 多次引用，地址只计一次
+		seen := make(map[string]bool)
+		parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+		for _, item := range parents {
+			parent, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := parent["name"].(string)
+			namespace, _ := parent["namespace"].(string)
+			if namespace == "" {
+				namespace = route.GetNamespace()
+			}
+			key := namespace + "/" + name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			gateways = append(gateways, key)
+			targets = append(targets, addresses[key]...)
+		}
+		labels := map[string]string{
+			"cluster":   cluster.Name,
+			"namespace": route.GetNamespace(),
+			"kind":      "HTTPRoute",
+			"object":    route.GetName(),
+			"gateway":   strings.Join(gateways, ","),
+		}
+		hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+		for _, host := range hostnames {
+			rst = append(rst, k.toRecords(host, "", targets, 0, labels)...)
+		}
+	}
+	return
+}
+
+// listDNSEndpointRecords 读取 ExternalDNS 的 DNSEndpoint，记录类型与 TTL 以其声明为准
+func (k *KubernetesDNS) listDNSEndpointRecords(cluster KubernetesCluster) (rst []Record) {
+	endpoints, err := cluster.Dynamic.Resource(dnsEndpointResource).Namespace(k.namespace()).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		logger.Debug(fmt.Sprintf("[ %s_%s ] list dnsendpoints in %s failed: %v", k.account.CloudProvider, k.account.CloudName, cluster.Name, err))
+		return
+	}
+	for _, ep := range endpoints.Items {
+		items, _, _ := unstructured.NestedSlice(ep.Object, "spec", "endpoints")
+		for _, item := range items {
+			endpoint, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			dnsName, _ := endpoint["dnsName"].(string)
+			recordType, _ := endpoint["recordType"].(string)
+			ttl, _, _ := unstructured.NestedInt64(endpoint, "recordTTL")
+			targets, _, _ := unstructured.NestedStringSlice(endpoint, "targets")
+			labels := map[string]string{
+				"cluster":   cluster.Name,
+				"namespace": ep.GetNamespace(),
+				"kind":      "DNSEndpoint",
+				"object":    ep.GetName(),
+			}
+			rst = append(rst, k.toRecords(dnsName, recordType, targets, ttl, labels)...)
+		}
+	}
+	return
+}
+
+// toRecords 每个目标地址生成一条记录，未指定记录类型时根据目标推断为 A/AAAA/CNAME
+func (k *KubernetesDNS) toRecords(host, recordType string, targets []string, ttl int64, labels map[string]string) (rst []Record) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	domain := k.domainOf(host)
+	if domain == "" {
+		return nil
+	}
+	name := "@"
+	if host != domain {
+		name = strings.TrimSuffix(host, "."+domain)
+	}
+	// 尚未分配地址的对象同样输出，便于发现未生效的配置
+	if len(targets) == 0 {
+		targets = []string{""}
+	}
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		t := recordType
+		if t == "" {
+			t = targetType(target)
+		}
+		object := labels["cluster"] + "/" + labels["namespace"] + "/" + labels["kind"] + "/" + labels["object"]
+		record := Record{
+			CloudProvider: k.account.CloudProvider,
+			CloudName:     k.account.CloudName,
+			DomainName:    domain,
+			RecordID:      recordID(domain, t, name, target+"|"+object),
+			RecordType:    t,
+			RecordName:    name,
+			RecordValue:   target,
+			RecordStatus:  "enable",
+			FullRecord:    host,
+			Labels:        make(map[string]string, len(labels)),
+		}
+		if ttl > 0 {
+			record.RecordTTL = strconv.FormatInt(ttl, 10)
+		}
+		if target == "" {
+			record.RecordStatus = "pending"
+		}
+		for k, v := range labels {
+			record.Labels[k] = v
+		}
+		rst = append(rst, record)
+	}
+	return
+}
+
+// domainOf 获取主机名所属的域名，优先匹配配置的 zones，否则使用可注册域名
+func (k *KubernetesDNS) domainOf(host string) string {
+	host = strings.TrimPrefix(host, "*.")
+	var matched string
+	for _, zone := range splitList(k.account.Options["zones"]) {
+		zone = strings.TrimSuffix(strings.ToLower(zone), ".")
+		if (host == zone || strings.HasSuffix(host, "."+zone)) && len(zone) > len(matched) {
+			matched = zone
+		}
+	}
+	if matched != "" {
+		return matched
+	}
+	domain, err := publicsuffix.Domain(host)
+	if err != nil {
+		return ""
+	}
+	return domain
+}
+
+// namespace 需要读取的命名空间，默认全部
+func (k *KubernetesDNS) namespace() string {
+	return k.account.Options["namespace"]
+}
+
+// targetType 根据目标地址推断记录类型
+func targetType(target string) string {
+	ip := net.ParseIP(target)
+	switch {
+	case target == "":
+		return ""
+	case ip == nil:
+		return "CNAME"
+	case ip.To4() != nil:
+		return "A"
+	default:
+		return "AAAA"
+	}
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestKubernetesDNS(t *testing.T) {
+	setupCache(t)
+	className := "nginx"
+	client := fake.NewSimpleClientset(&networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &className,
+			Rules:            []networkingv1.IngressRule{{Host: "www.example.com"}},
+			TLS:              []networkingv1.IngressTLS{{Hosts: []string{"www.example.com"}}},
+		},
+		Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+			Ingress: []networkingv1.IngressLoadBalancerIngress{{IP: "192.0.2.10"}},
+		}},
+	})
+	gateway := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata":   map[string]interface{}{"namespace": "infra", "name": "public"},
+		"spec": map[string]interface{}{"listeners": []interface{}{
+			map[string]interface{}{"name": "http", "hostname": "gw.example.com"},
+			map[string]interface{}{"name": "https", "hostname": "gw.example.com"},
+		}},
+		"status": map[string]interface{}{"addresses": []interface{}{
+			map[string]interface{}{"type": "IPAddress", "value": "192.0.2.20"},
+		}},
+	}}
+	// 同一 Gateway 的两个 listener 各引用一次
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "api"},
+		"spec": map[string]interface{}{
+			"parentRefs": []interface{}{
+				map[string]interface{}{"name": "public", "namespace": "infra", "sectionName": "http"},
+				map[string]interface{}{"name": "public", "namespace": "infra", "sectionName": "https"},
+			},
+			"hostnames": []interface{}{"api.example.com"},
+		},
+	}}
+	endpoint := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "externaldns.k8s.io/v1alpha1",
+		"kind":       "DNSEndpoint",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "mail"},
+		"spec": map[string]interface{}{"endpoints": []interface{}{
+			map[string]interface{}{
+				"dnsName":    "mail.example.org",
+				"recordType": "MX",
+				"recordTTL":  int64(300),
+				"targets":    []interface{}{"10 mx.example.org"},
+			},
+		}},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gatewayResource:     "GatewayList",
+		httpRouteResource:   "HTTPRouteList",
+		dnsEndpointResource: "DNSEndpointList",
+	})
+	// fake 由 Kind 推断资源名时会将 Gateway 推断为 gatewaies，按 GVR 显式创建
+	for resource, obj := range map[schema.GroupVersionResource]*unstructured.Unstructured{
+		gatewayResource:     gateway,
+		httpRouteResource:   route,
+		dnsEndpointResource: endpoint,
+	} {
+		if _, err := dynamicClient.Resource(resource).Namespace(obj.GetNamespace()).Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	account := public.Account{CloudProvider: public.KubernetesDnsProvider, CloudName: "test"}
+	k := NewKubernetesDNSWithClusters(account, []KubernetesCluster{{Name: "test", Client: client, Dynamic: dynamicClient}})
+
+	domains, err := k.ListDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 2 || domains[0].DomainName != "example.com" || domains[1].DomainName != "example.org" {
+		t.Fatalf("unexpected domains %+v", domains)
+	}
+
+	// ListRecords 复用 ListDomains 读取的结果，删除对象后结果不变
+	if err := client.NetworkingV1().Ingresses("default").Delete(context.Background(), "web", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := k.ListRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortRecords(records)
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want 4: %+v", len(records), records)
+	}
+	ids := make(map[string]bool)
+	for _, r := range records {
+		if ids[r.RecordID] {
+			t.Errorf("duplicate record %+v", r)
+		}
+		ids[r.RecordID] = true
+	}
+	want := map[string]struct{ recordType, value, kind string }{
+		"api.example.com":  {"A", "192.0.2.20", "HTTPRoute"},
+		"gw.example.com":   {"A", "192.0.2.20", "Gateway"},
+		"www.example.com":  {"A", "192.0.2.10", "Ingress"},
+		"mail.example.org": {"MX", "10 mx.example.org", "DNSEndpoint"},
+	}
+	for _, r := range records {
+		w, ok := want[r.FullRecord]
+		if !ok || r.RecordType != w.recordType || r.RecordValue != w.value || r.Labels["kind"] != w.kind {
+			t.Errorf("unexpected record %+v", r)
+		}
+	}
+	if r := records[0]; r.FullRecord == "api.example.com" && r.Labels["gateway"] != "infra/public" {
+		t.Errorf("unexpected gateway label %q", r.Labels["gateway"])
+	}
+
+	// 再次调用时重新读取集群
+	records, err = k.ListRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Errorf("got %d records after deleting the ingress, want 3", len(records))
+	}
+}
@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+type LinodeDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+type linodeDomain struct {
+	ID          int64  `json:"id"`
+	Domain      string `json:"domain"`
+	Type        string `json:"type"`
+	Status      string `json:"status"`
+	Description string `json:"description"`
+	Created     string `json:"created"`
+}
+
+type linodeRecord struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Target   string `json:"target"`
+	Priority int    `json:"priority"`
+	Weight   int    `json:"weight"`
+	TTLSec   int    `json:"ttl_sec"`
+	Updated  string `json:"updated"`
+}
+
+// NewLinodeClient 初始化客户端
+func NewLinodeClient(token string) (*restapi.Client, error) {
+	return restapi.NewClient("https://api.linode.com", restapi.WithBearerToken(token))
+}
+
+// NewLinodeDNS 创建 LinodeDNS 实例
+func NewLinodeDNS(account public.Account) (*LinodeDNS, error) {
+	client, err := NewLinodeClient(account.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &LinodeDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (l *LinodeDNS) ListDomains() ([]Domain, error) {
+	ld, err := NewLinodeDNS(l.account)
+	if err != nil {
+		return nil, err
+	}
+	l.client = ld.client
+	var dataObj []Domain
+	domains, err := l.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range domains {
+		dataObj = append(dataObj, Domain{
+			CloudProvider: l.account.CloudProvider,
+			CloudName:     l.account.CloudName,
+			DomainID:      strconv.FormatInt(v.ID, 10),
+			DomainName:    v.Domain,
+			DomainRemark:  v.Description,
+			DomainStatus:  foldStatus(v.Status),
+			CreatedDate:   v.Created,
+		})
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (l *LinodeDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	ld, err := NewLinodeDNS(l.account)
+	if err != nil {
+		return nil, err
+	}
+	l.client = ld.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + l.account.CloudProvider + "_" + l.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]linodeRecord)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domainName, domainId string) {
+			defer wg.Done()
+			<-ticker.C
+			records, err := l.getRecordList(domainId)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", l.account.CloudProvider, l.account.CloudName, err))
+			}
+			mu.Lock()
+			results[domainName] = records
+			mu.Unlock()
+		}(domain.DomainName, domain.DomainID)
+	}
+	wg.Wait()
+	for domain, records := range results {
+		for _, v := range records {
+			dataObj = append(dataObj, Record{
+				CloudProvider: l.account.CloudProvider,
+				CloudName:     l.account.CloudName,
+				DomainName:    domain,
+				RecordID:      strconv.FormatInt(v.ID, 10),
+				RecordType:    v.Type,
+				RecordName:    recordName(v.Name),
+				RecordValue:   v.Target,
+				RecordTTL:     strconv.Itoa(v.TTLSec),
+				RecordWeight:  strconv.Itoa(v.Weight),
+				RecordStatus:  "enable",
+				UpdateTime:    v.Updated,
+				FullRecord:    fullRecord(v.Name, domain),
+			})
+		}
+	}
+	return dataObj, nil
+}
+
+// https://techdocs.akamai.com/linode-api/reference/get-domains
+// getDomainList 获取域名列表
+func (l *LinodeDNS) getDomainList() (rst []linodeDomain, err error) {
+	page := 1
+	for {
+		var resp struct {
+			Data  []linodeDomain `json:"data"`
+			Page  int            `json:"page"`
+			Pages int            `json:"pages"`
+		}
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("page_size", "500")
+		if err := l.client.Get("/v4/domains", params, &resp); err != nil {
+			return nil, err
+		}
+		rst = append(rst, resp.Data...)
+		if resp.Page >= resp.Pages {
+			break
+		}
+		page++
+	}
+	return
+}
+
+// https://techdocs.akamai.com/linode-api/reference/get-domain-records
+// getRecordList 获取记录列表
+func (l *LinodeDNS) getRecordList(domainId string) (rst []linodeRecord, err error) {
+	page := 1
+	for {
+		var resp struct {
+			Data  []linodeRecord `json:"data"`
+			Page  int            `json:"page"`
+			Pages int            `json:"pages"`
+		}
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("page_size", "500")
+		if err := l.client.Get("/v4/domains/"+domainId+"/records", params, &resp); err != nil {
+			return rst, err
+		}
+		rst = append(rst, resp.Data...)
+		if resp.Page >= resp.Pages {
+			break
+		}
+		page++
+	}
+	return
+}
@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/golang-module/carbon/v2"
+	"github.com/weppos/publicsuffix-go/publicsuffix"
+)
+
+type NamecheapDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+type namecheapResponse struct {
+	XMLName xml.Name `xml:"ApiResponse"`
+	Status  string   `xml:"Status,attr"`
+	Errors  []struct {
+		Number  string `xml:"Number,attr"`
+		Message string `xml:",chardata"`
+	} `xml:"Errors>Error"`
+	CommandResponse struct {
+		Domains []namecheapDomain `xml:"DomainGetListResult>Domain"`
+		Hosts   []namecheapHost   `xml:"DomainDNSGetHostsResult>host"`
+		Paging  struct {
+			TotalItems  int `xml:"TotalItems"`
+			CurrentPage int `xml:"CurrentPage"`
+			PageSize    int `xml:"PageSize"`
+		} `xml:"Paging"`
+	} `xml:"CommandResponse"`
+}
+
+type namecheapDomain struct {
+	ID        string `xml:"ID,attr"`
+	Name      string `xml:"Name,attr"`
+	Created   string `xml:"Created,attr"`
+	Expires   string `xml:"Expires,attr"`
+	IsExpired bool   `xml:"IsExpired,attr"`
+	IsLocked  bool   `xml:"IsLocked,attr"`
+	AutoRenew bool   `xml:"AutoRenew,attr"`
+	IsOurDNS  bool   `xml:"IsOurDNS,attr"`
+}
+
+type namecheapHost struct {
+	HostID   string `xml:"HostId,attr"`
+	Name     string `xml:"Name,attr"`
+	Type     string `xml:"Type,attr"`
+	Address  string `xml:"Address,attr"`
+	MXPref   string `xml:"MXPref,attr"`
+	TTL      string `xml:"TTL,attr"`
+	IsActive bool   `xml:"IsActive,attr"`
+}
+
+// NewNamecheapClient 初始化客户端
+func NewNamecheapClient(endpoint string) (*restapi.Client, error) {
+	return restapi.NewClient(endpoint, restapi.WithXMLResponse())
+}
+
+// NewNamecheapDNS 创建 NamecheapDNS 实例
+func NewNamecheapDNS(account public.Account) (*NamecheapDNS, error) {
+	if account.Options["clientIp"] == "" {
+		return nil, errors.New("missing namecheap clientIp")
+	}
+	client, err := NewNamecheapClient(accountEndpoint(account, "https://api.namecheap.com"))
+	if err != nil {
+		return nil, err
+	}
+	return &NamecheapDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (n *NamecheapDNS) ListDomains() ([]Domain, error) {
+	nd, err := NewNamecheapDNS(n.account)
+	if err != nil {
+		return nil, err
+	}
+	n.client = nd.client
+	var dataObj []Domain
+	domains, err := n.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range domains {
+		d := Domain{
+			CloudProvider: n.account.CloudProvider,
+			CloudName:     n.account.CloudName,
+			DomainID:      v.ID,
+			DomainName:    strings.ToLower(v.Name),
+			DomainStatus:  "enable",
+			CreatedDate:   namecheapDate(v.Created),
+			ExpiryDate:    namecheapDate(v.Expires),
+			AutoRenew:     strconv.FormatBool(v.AutoRenew),
+			Locked:        strconv.FormatBool(v.IsLocked),
+			Labels:        map[string]string{"namecheap_dns": strconv.FormatBool(v.IsOurDNS)},
+		}
+		if v.IsExpired {
+			d.DomainStatus = "expired"
+		}
+		if d.ExpiryDate != "" {
+			d.DaysUntilExpiry = carbon.Now().DiffInDays(carbon.Parse(d.ExpiryDate))
+		}
+		dataObj = append(dataObj, d)
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (n *NamecheapDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	nd, err := NewNamecheapDNS(n.account)
+	if err != nil {
+		return nil, err
+	}
+	n.client = nd.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + n.account.CloudProvider + "_" + n.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]namecheapHost)
+	// namecheap 接口限制每分钟 50 次请求
+	ticker := time.NewTicker(1200 * time.Millisecond)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		// 未使用 namecheap 解析的域名没有记录，接口会返回错误
+		if domain.Labels["namecheap_dns"] == "false" {
+			continue
+		}
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			<-ticker.C
+			records, err := n.getRecordList(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", n.account.CloudProvider, n.account.CloudName, err))
+			}
+			if len(records) == 0 {
+				return
+			}
+			mu.Lock()
+			results[domain] = records
+			mu.Unlock()
+		}(domain.DomainName)
+	}
+	wg.Wait()
+	for domain, records := range results {
+		for _, v := range records {
+			status := "enable"
+			if !v.IsActive {
+				status = "disable"
+			}
+			dataObj = append(dataObj, Record{
+				CloudProvider: n.account.CloudProvider,
+				CloudName:     n.account.CloudName,
+				DomainName:    domain,
+				RecordID:      v.HostID,
+				RecordType:    v.Type,
+				RecordName:    recordName(v.Name),
+				RecordValue:   v.Address,
+				RecordTTL:     v.TTL,
+				RecordStatus:  status,
+				FullRecord:    fullRecord(v.Name, domain),
+			})
+		}
+	}
+	return dataObj, nil
+}
+
+// call 调用 namecheap 接口，公共参数由此处统一附加
+func (n *NamecheapDNS) call(command string, params url.Values) (*namecheapResponse, error) {
+	username := n.account.Options["username"]
+	if username == "" {
+		username = n.account.SecretID
+	}
+	params.Set("ApiUser", n.account.SecretID)
+	params.Set("ApiKey", n.account.SecretKey)
+	params.Set("UserName", username)
+	params.Set("ClientIp", n.account.Options["clientIp"])
+	params.Set("Command", command)
+	var resp namecheapResponse
+	if err := n.client.Get("/xml.response", params, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "OK" {
+		if len(resp.Errors) > 0 {
+			return nil, fmt.Errorf("namecheap error %s: %s", resp.Errors[0].Number, resp.Errors[0].Message)
+		}
+		return nil, fmt.Errorf("namecheap response status: %s", resp.Status)
+	}
+	return &resp, nil
+}
+
+// https://www.namecheap.com/support/api/methods/domains/get-list/
+// getDomainList 获取域名列表
+func (n *NamecheapDNS) getDomainList() (rst []namecheapDomain, err error) {
+	page := 1
+	for {
+		params := url.Values{}
+		params.Set("Page", strconv.Itoa(page))
+		params.Set("PageSize", "100")
+		resp, err := n.call("namecheap.domains.getList", params)
+		if err != nil {
+			return nil, err
+		}
+		rst = append(rst, resp.CommandResponse.Domains...)
+		paging := resp.CommandResponse.Paging
+		if len(resp.CommandResponse.Domains) == 0 || paging.CurrentPage*paging.PageSize >= paging.TotalItems {
+			break
+		}
+		page++
+	}
+	return
+}
+
+// https://www.namecheap.com/support/api/methods/domains-dns/get-hosts/
+// getRecordList 获取记录列表
+func (n *NamecheapDNS) getRecordList(domain string) ([]namecheapHost, error) {
+	dn, err := publicsuffix.Parse(domain)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("SLD", dn.SLD)
+	params.Set("TLD", dn.TLD)
+	resp, err := n.call("namecheap.domains.dns.getHosts", params)
+	if err != nil {
+		return nil, err
+	}
+	return resp.CommandResponse.Hosts, nil
+}
+
+// namecheapDate 将 namecheap 返回的 MM/DD/YYYY 日期转换为 YYYY-MM-DD
+func namecheapDate(date string) string {
+	t, err := time.Parse("01/02/2006", date)
+	if err != nil {
+		return date
+	}
+	return t.Format(time.DateOnly)
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+)
+
+func TestNamecheapDNS(t *testing.T) {
+	setupCache(t)
+	srv := fixtureServer(t, func(r *http.Request) string {
+		return r.URL.Query().Get("Command") + " " + r.URL.Query().Get("SLD")
+	}, map[string]string{
+		"namecheap.domains.getList ":             "namecheap/domains.getList.xml",
+		"namecheap.domains.dns.getHosts example": "namecheap/domains.dns.getHosts.xml",
+	})
+	account := public.Account{
+		CloudProvider: public.NamecheapDnsProvider,
+		CloudName:     "test",
+		SecretID:      "user",
+		SecretKey:     "key",
+		Endpoint:      srv.URL,
+		Options:       map[string]string{"clientIp": "127.0.0.1"},
+	}
+	n := &NamecheapDNS{account: account}
+
+	domains, err := n.ListDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 2 {
+		t.Fatalf("got %d domains, want 2", len(domains))
+	}
+	d := domains[0]
+	if d.DomainID != "127" || d.DomainName != "example.com" || d.CreatedDate != "2016-02-15" || d.ExpiryDate != "2099-02-15" ||
+		d.Locked != "true" || d.AutoRenew != "false" || d.Labels["namecheap_dns"] != "true" {
+		t.Errorf("unexpected domain %+v", d)
+	}
+	if domains[1].Labels["namecheap_dns"] != "false" {
+		t.Errorf("unexpected domain %+v", domains[1])
+	}
+
+	// parked.net 未使用 namecheap 解析，不应请求其记录
+	cacheDomains(t, account, domains)
+	records, err := n.ListRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortRecords(records)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if r := records[0]; r.RecordID != "12" || r.RecordName != "@" || r.FullRecord != "example.com" || r.RecordValue != "1.2.3.4" || r.RecordTTL != "1800" || r.RecordStatus != "enable" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if r := records[1]; r.FullRecord != "www.example.com" || r.RecordType != "CNAME" || r.RecordStatus != "disable" {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/golang-module/carbon/v2"
+)
+
+type NameComDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+type nameComDomain struct {
+	DomainName       string `json:"domainName"`
+	Locked           bool   `json:"locked"`
+	AutorenewEnabled bool   `json:"autorenewEnabled"`
+	ExpireDate       string `json:"expireDate"`
+	CreateDate       string `json:"createDate"`
+}
+
+type nameComRecord struct {
+	ID         int64  `json:"id"`
+	DomainName string `json:"domainName"`
+	Host       string `json:"host"`
+	Fqdn       string `json:"fqdn"`
+	Type       string `json:"type"`
+	Answer     string `json:"answer"`
+	TTL        int    `json:"ttl"`
+	Priority   int    `json:"priority"`
+}
+
+// NewNameComClient 初始化客户端
+func NewNameComClient(endpoint, username, token string) (*restapi.Client, error) {
+	return restapi.NewClient(endpoint, restapi.WithBasicAuth(username, token))
+}
+
+// NewNameComDNS 创建 NameComDNS 实例
+func NewNameComDNS(account public.Account) (*NameComDNS, error) {
+	client, err := NewNameComClient(accountEndpoint(account, "https://api.name.com"), account.SecretID, account.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &NameComDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (n *NameComDNS) ListDomains() ([]Domain, error) {
+	nd, err := NewNameComDNS(n.account)
+	if err != nil {
+		return nil, err
+	}
+	n.client = nd.client
+	var dataObj []Domain
+	domains, err := n.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range domains {
+		d := Domain{
+			CloudProvider: n.account.CloudProvider,
+			CloudName:     n.account.CloudName,
+			DomainID:      v.DomainName,
+			DomainName:    v.DomainName,
+			DomainStatus:  "enable",
+			CreatedDate:   v.CreateDate,
+			ExpiryDate:    v.ExpireDate,
+			AutoRenew:     strconv.FormatBool(v.AutorenewEnabled),
+			Locked:        strconv.FormatBool(v.Locked),
+		}
+		if d.ExpiryDate != "" {
+			d.DaysUntilExpiry = carbon.Now().DiffInDays(carbon.Parse(d.ExpiryDate))
+		}
+		dataObj = append(dataObj, d)
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (n *NameComDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	nd, err := NewNameComDNS(n.account)
+	if err != nil {
+		return nil, err
+	}
+	n.client = nd.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + n.account.CloudProvider + "_" + n.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]nameComRecord)
+	// name.com 接口限制每秒 20 次请求
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			<-ticker.C
+			records, err := n.getRecordList(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", n.account.CloudProvider, n.account.CloudName, err))
+			}
+			mu.Lock()
+			results[domain] = records
+			mu.Unlock()
+		}(domain.DomainName)
+	}
+	wg.Wait()
+	for domain, records := range results {
+		for _, v := range records {
+			dataObj = append(dataObj, Record{
+				CloudProvider: n.account.CloudProvider,
+				CloudName:     n.account.CloudName,
+				DomainName:    domain,
+				RecordID:      strconv.FormatInt(v.ID, 10),
+				RecordType:    v.Type,
+				RecordName:    recordName(v.Host),
+				RecordValue:   v.Answer,
+				RecordTTL:     strconv.Itoa(v.TTL),
+				RecordStatus:  "enable",
+				FullRecord:    strings.TrimSuffix(v.Fqdn, "."),
+			})
+		}
+	}
+	return dataObj, nil
+}
+
+// https://docs.name.com/docs/api-reference/domains/list-domains
+// getDomainList 获取域名列表
+func (n *NameComDNS) getDomainList() (rst []nameComDomain, err error) {
+	page := 1
+	for {
+		var resp struct {
+			Domains  []nameComDomain `json:"domains"`
+			NextPage int             `json:"nextPage"`
+		}
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("perPage", "1000")
+		if err := n.client.Get("/v4/domains", params, &resp); err != nil {
+			return nil, err
+		}
+		rst = append(rst, resp.Domains...)
+		if resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+	return
+}
+
+// https://docs.name.com/docs/api-reference/dns/list-records
+// getRecordList 获取记录列表
+func (n *NameComDNS) getRecordList(domain string) (rst []nameComRecord, err error) {
+	page := 1
+	for {
+		var resp struct {
+			Records  []nameComRecord `json:"records"`
+			NextPage int             `json:"nextPage"`
+		}
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("perPage", "1000")
+		if err := n.client.Get("/v4/domains/"+domain+"/records", params, &resp); err != nil {
+			return rst, err
+		}
+		rst = append(rst, resp.Records...)
+		if resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+	return
+}
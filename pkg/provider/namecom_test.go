@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+)
+
+func TestNameComDNS(t *testing.T) {
+	setupCache(t)
+	srv := fixtureServer(t, func(r *http.Request) string {
+		if user, token, ok := r.BasicAuth(); !ok || user != "user" || token != "token" {
+			return "unauthorized"
+		}
+		return r.URL.Path
+	}, map[string]string{
+		"/v4/domains":                     "namecom/domains.json",
+		"/v4/domains/example.org/records": "namecom/records.json",
+	})
+	account := public.Account{
+		CloudProvider: public.NameComDnsProvider,
+		CloudName:     "test",
+		SecretID:      "user",
+		SecretKey:     "token",
+		Endpoint:      srv.URL,
+	}
+	n := &NameComDNS{account: account}
+
+	domains, err := n.ListDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 1 {
+		t.Fatalf("got %d domains, want 1", len(domains))
+	}
+	if d := domains[0]; d.DomainName != "example.org" || d.Locked != "true" || d.AutoRenew != "true" || d.ExpiryDate == "" {
+		t.Errorf("unexpected domain %+v", d)
+	}
+
+	cacheDomains(t, account, domains)
+	records, err := n.ListRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortRecords(records)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if r := records[0]; r.RecordID != "12345" || r.RecordName != "@" || r.FullRecord != "example.org" || r.RecordTTL != "300" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if r := records[1]; r.RecordName != "mail" || r.FullRecord != "mail.example.org" || r.RecordType != "MX" {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
@@ -0,0 +1,262 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+// NS1DNS 通过 NS1 的 API 获取域与记录，流量调度记录会按 answer 拆分为多条记录
+type NS1DNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+type ns1Zone struct {
+	ID      string          `json:"id"`
+	Zone    string          `json:"zone"`
+	Serial  int64           `json:"serial"`
+	DNSSEC  bool            `json:"dnssec"`
+	Primary json.RawMessage `json:"primary"`
+	Records []struct {
+		ID           string   `json:"id"`
+		Domain       string   `json:"domain"`
+		Type         string   `json:"type"`
+		TTL          int      `json:"ttl"`
+		Tier         int      `json:"tier"`
+		ShortAnswers []string `json:"short_answers"`
+	} `json:"records"`
+}
+
+type ns1Record struct {
+	ID      string `json:"id"`
+	Domain  string `json:"domain"`
+	Type    string `json:"type"`
+	TTL     int    `json:"ttl"`
+	Answers []struct {
+		ID     string                     `json:"id"`
+		Answer []interface{}              `json:"answer"`
+		Region string                     `json:"region"`
+		Meta   map[string]json.RawMessage `json:"meta"`
+	} `json:"answers"`
+	Filters []struct {
+		Filter   string `json:"filter"`
+		Disabled bool   `json:"disabled"`
+	} `json:"filters"`
+}
+
+// NewNS1Client 初始化客户端
+func NewNS1Client(endpoint, apiKey string) (*restapi.Client, error) {
+	return restapi.NewClient(endpoint, restapi.WithHeader("X-NSONE-Key", apiKey))
+}
+
+// NewNS1DNS 创建 NS1DNS 实例
+func NewNS1DNS(account public.Account) (*NS1DNS, error) {
+	client, err := NewNS1Client(accountEndpoint(account, "https://api.nsone.net"), account.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &NS1DNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (n *NS1DNS) ListDomains() ([]Domain, error) {
+	nd, err := NewNS1DNS(n.account)
+	if err != nil {
+		return nil, err
+	}
+	n.client = nd.client
+	var dataObj []Domain
+	zones, err := n.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range zones {
+		kind := "primary"
+		if len(v.Primary) > 0 && strings.Contains(string(v.Primary), `"enabled":true`) {
+			kind = "secondary"
+		}
+		dataObj = append(dataObj, Domain{
+			CloudProvider: n.account.CloudProvider,
+			CloudName:     n.account.CloudName,
+			DomainID:      v.ID,
+			DomainName:    v.Zone,
+			DomainStatus:  "enable",
+			SOASerial:     v.Serial,
+			Labels: map[string]string{
+				"kind":   kind,
+				"dnssec": strconv.FormatBool(v.DNSSEC),
+			},
+		})
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (n *NS1DNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	nd, err := NewNS1DNS(n.account)
+	if err != nil {
+		return nil, err
+	}
+	n.client = nd.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + n.account.CloudProvider + "_" + n.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	// ns1 接口有频率限制，所有请求共用一个 ticker
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			<-ticker.C
+			records, err := n.getRecordList(domain, ticker)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", n.account.CloudProvider, n.account.CloudName, err))
+			}
+			mu.Lock()
+			dataObj = append(dataObj, records...)
+			mu.Unlock()
+		}(domain.DomainName)
+	}
+	wg.Wait()
+	return dataObj, nil
+}
+
+// https://ns1.com/api
+// getDomainList 获取域列表
+func (n *NS1DNS) getDomainList() (rst []ns1Zone, err error) {
+	err = n.client.Get("/v1/zones", nil, &rst)
+	return
+}
+
+// getRecordList 获取域中的记录，普通记录直接使用 short_answers，带有调度策略的记录需要获取详情
+func (n *NS1DNS) getRecordList(domain string, ticker *time.Ticker) (rst []Record, err error) {
+	var zone ns1Zone
+	if err := n.client.Get("/v1/zones/"+domain, nil, &zone); err != nil {
+		return nil, err
+	}
+	for _, v := range zone.Records {
+		name := recordName(strings.TrimSuffix(strings.TrimSuffix(v.Domain, domain), "."))
+		if v.Tier <= 1 {
+			for _, answer := range v.ShortAnswers {
+				value := strings.TrimSuffix(answer, ".")
+				rst = append(rst, Record{
+					CloudProvider: n.account.CloudProvider,
+					CloudName:     n.account.CloudName,
+					DomainName:    domain,
+					RecordID:      recordID(domain, v.Type, name, value),
+					RecordType:    v.Type,
+					RecordName:    name,
+					RecordValue:   value,
+					RecordTTL:     strconv.Itoa(v.TTL),
+					RecordStatus:  "enable",
+					FullRecord:    v.Domain,
+				})
+			}
+			continue
+		}
+		<-ticker.C
+		var record ns1Record
+		if err := n.client.Get("/v1/zones/"+domain+"/"+v.Domain+"/"+v.Type, nil, &record); err != nil {
+			logger.Error(fmt.Sprintf("[ %s_%s ] get record %s %s failed: %v", n.account.CloudProvider, n.account.CloudName, v.Domain, v.Type, err))
+			continue
+		}
+		rst = append(rst, n.toRecords(domain, name, record)...)
+	}
+	return rst, nil
+}
+
+// toRecords 每个 answer 生成一条记录，过滤链、区域与 answer 的 meta 保存在 Labels 中
+func (n *NS1DNS) toRecords(domain, name string, record ns1Record) (rst []Record) {
+	var filters []string
+	for _, f := range record.Filters {
+		if !f.Disabled {
+			filters = append(filters, f.Filter)
+		}
+	}
+	for i, answer := range record.Answers {
+		var values []string
+		for _, v := range answer.Answer {
+			values = append(values, fmt.Sprint(v))
+		}
+		value := strings.TrimSuffix(strings.Join(values, " "), ".")
+		labels := map[string]string{
+			"filters": strings.Join(filters, ","),
+		}
+		if answer.Region != "" {
+			labels["region"] = answer.Region
+		}
+		for k, v := range answer.Meta {
+			labels["meta_"+k] = ns1MetaValue(v)
+		}
+		id := answer.ID
+		if id == "" {
+			id = record.ID + "_" + strconv.Itoa(i)
+		}
+		status := "enable"
+		if labels["meta_up"] == "false" {
+			status = "disable"
+		}
+		rst = append(rst, Record{
+			CloudProvider: n.account.CloudProvider,
+			CloudName:     n.account.CloudName,
+			DomainName:    domain,
+			RecordID:      id,
+			RecordType:    record.Type,
+			RecordName:    name,
+			RecordValue:   value,
+			RecordTTL:     strconv.Itoa(record.TTL),
+			RecordWeight:  labels["meta_weight"],
+			RecordStatus:  status,
+			FullRecord:    record.Domain,
+			Labels:        labels,
+		})
+	}
+	return
+}
+
+// ns1MetaValue meta 的值可能是字符串、数字、数组或数据源 {"feed": "id"}，统一转换为字符串
+func ns1MetaValue(raw json.RawMessage) string {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		sort.Strings(values)
+		return strings.Join(values, ",")
+	case map[string]interface{}:
+		return string(raw)
+	default:
+		return fmt.Sprint(v)
+	}
+}
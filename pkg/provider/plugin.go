@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+// PluginProtocolVersion 插件协议版本，插件可据此判断兼容性
+const PluginProtocolVersion = 1
+
+const (
+	PluginMethodListDomains = "ListDomains"
+	PluginMethodListRecords = "ListRecords"
+)
+
+// PluginRequest 每次调用时写入插件标准输入的请求
+type PluginRequest struct {
+	ProtocolVersion int               `json:"protocol_version"`
+	Method          string            `json:"method"`
+	CloudProvider   string            `json:"cloud_provider"`
+	Account         map[string]string `json:"account"` // 账号在配置文件中的原始字段
+	Domains         []Domain          `json:"domains,omitempty"`
+}
+
+// PluginResponse 插件写入标准输出的响应，error 不为空时视为调用失败
+type PluginResponse struct {
+	Domains []Domain `json:"domains,omitempty"`
+	Records []Record `json:"records,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// PluginDNS 外部插件实现的 DNS 提供商，每次调用启动一次插件进程
+type PluginDNS struct {
+	account public.Account
+	plugin  public.Plugin
+}
+
+// NewPluginDNS 创建插件提供商实例
+func NewPluginDNS(account public.Account, plugin public.Plugin) *PluginDNS {
+	return &PluginDNS{
+		account: account,
+		plugin:  plugin,
+	}
+}
+
+// RegisterPlugins 将配置文件中声明的插件注册到 Factory，同名时覆盖内置提供商
+func RegisterPlugins(plugins map[string]public.Plugin) {
+	for name, plugin := range plugins {
+		if plugin.Command == "" {
+			logger.Error(fmt.Sprintf("[ %s ] plugin command is empty, skipped", name))
+			continue
+		}
+		if _, exists := Factory.dnsProviders[strings.ToLower(name)]; exists {
+			logger.Warning(fmt.Sprintf("[ %s ] plugin overrides the built-in provider", name))
+		}
+		Factory.Register(name, func(account map[string]string) DNSProvider {
+			return NewPluginDNS(public.Account{
+				CloudProvider: name,
+				CloudName:     account["name"],
+				SecretID:      account["secretId"],
+				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			}, plugin)
+		})
+	}
+}
+
+// ListDomains 获取域名列表
+func (p *PluginDNS) ListDomains() ([]Domain, error) {
+	rsp, err := p.call(PluginRequest{Method: PluginMethodListDomains})
+	if err != nil {
+		return nil, err
+	}
+	for i := range rsp.Domains {
+		rsp.Domains[i].CloudProvider = p.account.CloudProvider
+		rsp.Domains[i].CloudName = p.account.CloudName
+	}
+	return rsp.Domains, nil
+}
+
+// ListRecords 获取记录列表，请求中携带已缓存的域名列表，插件可直接使用
+func (p *PluginDNS) ListRecords() ([]Record, error) {
+	var domains []Domain
+	domainListCacheKey := public.DomainList + "_" + p.account.CloudProvider + "_" + p.account.CloudName
+	if domainList, err := public.Cache.Get(domainListCacheKey); err == nil {
+		if err := json.Unmarshal(domainList, &domains); err != nil {
+			logger.Error(fmt.Sprintf("[ %s ] json.Unmarshal error: %v", domainListCacheKey, err))
+		}
+	}
+	rsp, err := p.call(PluginRequest{Method: PluginMethodListRecords, Domains: domains})
+	if err != nil {
+		return nil, err
+	}
+	for i := range rsp.Records {
+		rsp.Records[i].CloudProvider = p.account.CloudProvider
+		rsp.Records[i].CloudName = p.account.CloudName
+		if rsp.Records[i].RecordID == "" {
+			r := rsp.Records[i]
+			rsp.Records[i].RecordID = recordID(r.DomainName, r.RecordType, r.RecordName, r.RecordValue)
+		}
+	}
+	return rsp.Records, nil
+}
+
+// call 启动插件进程，写入请求并读取响应，插件的标准错误输出记录到调试日志
+func (p *PluginDNS) call(req PluginRequest) (*PluginResponse, error) {
+	timeout := 60 * time.Second
+	if p.plugin.Timeout != "" {
+		d, err := time.ParseDuration(p.plugin.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plugin timeout %q: %w", p.plugin.Timeout, err)
+		}
+		timeout = d
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req.ProtocolVersion = PluginProtocolVersion
+	req.CloudProvider = p.account.CloudProvider
+	req.Account = p.account.Options
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.plugin.Command, p.plugin.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+	for k, v := range p.plugin.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	err = cmd.Run()
+	if stderr.Len() > 0 {
+		logger.Debug(fmt.Sprintf("[ %s_%s ] plugin %s stderr: %s", p.account.CloudProvider, p.account.CloudName, req.Method, strings.TrimSpace(stderr.String())))
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("plugin %s timed out after %s", req.Method, timeout)
+	}
+
+	var rsp PluginResponse
+	if stdout.Len() > 0 {
+		if jsonErr := json.Unmarshal(stdout.Bytes(), &rsp); jsonErr != nil {
+			if err != nil {
+				return nil, fmt.Errorf("plugin %s failed: %w", req.Method, err)
+			}
+			return nil, fmt.Errorf("decode plugin %s response failed: %w", req.Method, jsonErr)
+		}
+	}
+	if rsp.Error != "" {
+		return nil, fmt.Errorf("plugin %s failed: %s", req.Method, rsp.Error)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %w", req.Method, err)
+	}
+	return &rsp, nil
+}
@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+)
+
+// TestPluginHelperProcess 作为插件进程运行，由 PLUGIN_HELPER_MODE 决定行为，直接执行时跳过
+func TestPluginHelperProcess(t *testing.T) {
+	mode := os.Getenv("PLUGIN_HELPER_MODE")
+	if mode == "" {
+		return
+	}
+	var req PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	switch mode {
+	case "ok":
+		var rsp PluginResponse
+		switch req.Method {
+		case PluginMethodListDomains:
+			rsp.Domains = []Domain{{DomainID: "1", DomainName: req.Account["domain"]}}
+		case PluginMethodListRecords:
+			for _, d := range req.Domains {
+				rsp.Records = append(rsp.Records, Record{DomainName: d.DomainName, RecordType: "A", RecordName: "www", RecordValue: "1.2.3.4"})
+			}
+		}
+		_ = json.NewEncoder(os.Stdout).Encode(rsp)
+	case "error":
+		_ = json.NewEncoder(os.Stdout).Encode(PluginResponse{Error: "invalid credentials"})
+	case "garbage":
+		fmt.Println("not json")
+	case "exit":
+		fmt.Fprintln(os.Stderr, "crashed")
+		os.Exit(3)
+	case "sleep":
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+// helperPlugin 返回以当前测试程序作为插件进程的配置
+func helperPlugin(mode, timeout string) *PluginDNS {
+	return NewPluginDNS(public.Account{
+		CloudProvider: "helper",
+		CloudName:     "test",
+		Options:       map[string]string{"name": "test", "domain": "example.com"},
+	}, public.Plugin{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestPluginHelperProcess$"},
+		Env:     map[string]string{"PLUGIN_HELPER_MODE": mode},
+		Timeout: timeout,
+	})
+}
+
+func TestPluginDNS(t *testing.T) {
+	setupCache(t)
+	p := helperPlugin("ok", "")
+	domains, err := p.ListDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 1 || domains[0].DomainName != "example.com" || domains[0].CloudProvider != "helper" || domains[0].CloudName != "test" {
+		t.Fatalf("unexpected domains %+v", domains)
+	}
+
+	// 记录请求中携带缓存的域名列表，未返回记录 ID 时生成稳定的 ID
+	cacheDomains(t, p.account, domains)
+	records, err := p.ListRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].DomainName != "example.com" || records[0].CloudName != "test" ||
+		records[0].RecordID != recordID("example.com", "A", "www", "1.2.3.4") {
+		t.Errorf("unexpected records %+v", records)
+	}
+}
+
+func TestPluginDNSErrors(t *testing.T) {
+	for _, tc := range []struct {
+		mode, timeout, want string
+	}{
+		{"error", "", "plugin ListDomains failed: invalid credentials"},
+		{"garbage", "", "decode plugin ListDomains response failed"},
+		{"exit", "", "plugin ListDomains failed: exit status 3"},
+		{"sleep", "200ms", "plugin ListDomains timed out after 200ms"},
+		{"ok", "soon", "invalid plugin timeout"},
+	} {
+		_, err := helperPlugin(tc.mode, tc.timeout).ListDomains()
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got error %v, want %q", tc.mode, err, tc.want)
+		}
+	}
+}
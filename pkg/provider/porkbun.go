@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/golang-module/carbon/v2"
+)
+
+type PorkbunDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+// porkbunValue porkbun 接口中的数字和布尔值可能以字符串或数字返回，统一按字符串处理
+type porkbunValue string
+
+func (v *porkbunValue) UnmarshalJSON(data []byte) error {
+	*v = porkbunValue(strings.Trim(string(data), `"`))
+	return nil
+}
+
+// bool 将 "1"、1、true 识别为 true
+func (v porkbunValue) bool() string {
+	switch string(v) {
+	case "":
+		return ""
+	case "1", "true":
+		return "true"
+	}
+	return "false"
+}
+
+type porkbunDomain struct {
+	Domain       string       `json:"domain"`
+	Status       string       `json:"status"`
+	CreateDate   string       `json:"createDate"`
+	ExpireDate   string       `json:"expireDate"`
+	SecurityLock porkbunValue `json:"securityLock"`
+	AutoRenew    porkbunValue `json:"autoRenew"`
+}
+
+type porkbunRecord struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	Type    string       `json:"type"`
+	Content string       `json:"content"`
+	TTL     porkbunValue `json:"ttl"`
+	Prio    porkbunValue `json:"prio"`
+	Notes   string       `json:"notes"`
+}
+
+// NewPorkbunClient 初始化客户端
+func NewPorkbunClient(endpoint string) (*restapi.Client, error) {
+	return restapi.NewClient(endpoint)
+}
+
+// NewPorkbunDNS 创建 PorkbunDNS 实例
+func NewPorkbunDNS(account public.Account) (*PorkbunDNS, error) {
+	client, err := NewPorkbunClient(accountEndpoint(account, "https://api.porkbun.com/api/json/v3"))
+	if err != nil {
+		return nil, err
+	}
+	return &PorkbunDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (p *PorkbunDNS) ListDomains() ([]Domain, error) {
+	pd, err := NewPorkbunDNS(p.account)
+	if err != nil {
+		return nil, err
+	}
+	p.client = pd.client
+	var dataObj []Domain
+	domains, err := p.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range domains {
+		d := Domain{
+			CloudProvider: p.account.CloudProvider,
+			CloudName:     p.account.CloudName,
+			DomainID:      v.Domain,
+			DomainName:    v.Domain,
+			DomainStatus:  foldStatus(v.Status),
+			CreatedDate:   v.CreateDate,
+			ExpiryDate:    v.ExpireDate,
+			AutoRenew:     v.AutoRenew.bool(),
+			Locked:        v.SecurityLock.bool(),
+		}
+		if d.ExpiryDate != "" {
+			d.DaysUntilExpiry = carbon.Now().DiffInDays(carbon.Parse(d.ExpiryDate))
+		}
+		dataObj = append(dataObj, d)
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (p *PorkbunDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	pd, err := NewPorkbunDNS(p.account)
+	if err != nil {
+		return nil, err
+	}
+	p.client = pd.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + p.account.CloudProvider + "_" + p.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]porkbunRecord)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			<-ticker.C
+			records, err := p.getRecordList(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", p.account.CloudProvider, p.account.CloudName, err))
+			}
+			if len(records) == 0 {
+				return
+			}
+			mu.Lock()
+			results[domain] = records
+			mu.Unlock()
+		}(domain.DomainName)
+	}
+	wg.Wait()
+	for domain, records := range results {
+		for _, v := range records {
+			// porkbun 返回的 name 为完整记录
+			name := strings.TrimSuffix(strings.TrimSuffix(v.Name, domain), ".")
+			dataObj = append(dataObj, Record{
+				CloudProvider: p.account.CloudProvider,
+				CloudName:     p.account.CloudName,
+				DomainName:    domain,
+				RecordID:      v.ID,
+				RecordType:    v.Type,
+				RecordName:    recordName(name),
+				RecordValue:   v.Content,
+				RecordTTL:     string(v.TTL),
+				RecordStatus:  "enable",
+				RecordRemark:  v.Notes,
+				FullRecord:    v.Name,
+			})
+		}
+	}
+	return dataObj, nil
+}
+
+// auth porkbun 的认证信息放在请求体中
+func (p *PorkbunDNS) auth() map[string]string {
+	return map[string]string{
+		"apikey":       p.account.SecretID,
+		"secretapikey": p.account.SecretKey,
+	}
+}
+
+// https://porkbun.com/api/json/v3/documentation#Domain%20List%20All
+// getDomainList 获取域名列表，每次最多返回 1000 个
+func (p *PorkbunDNS) getDomainList() (rst []porkbunDomain, err error) {
+	start := 0
+	for {
+		body := p.auth()
+		body["start"] = strconv.Itoa(start)
+		var resp struct {
+			Status  string          `json:"status"`
+			Message string          `json:"message"`
+			Domains []porkbunDomain `json:"domains"`
+		}
+		if err := p.client.Post("/domain/listAll", body, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Status != "SUCCESS" {
+			return nil, fmt.Errorf("porkbun response status %s: %s", resp.Status, resp.Message)
+		}
+		rst = append(rst, resp.Domains...)
+		if len(resp.Domains) < 1000 {
+			break
+		}
+		start += 1000
+	}
+	return
+}
+
+// https://porkbun.com/api/json/v3/documentation#DNS%20Retrieve%20Records%20by%20Domain%20or%20ID
+// getRecordList 获取记录列表
+func (p *PorkbunDNS) getRecordList(domain string) ([]porkbunRecord, error) {
+	var resp struct {
+		Status  string          `json:"status"`
+		Message string          `json:"message"`
+		Records []porkbunRecord `json:"records"`
+	}
+	if err := p.client.Post("/dns/retrieve/"+domain, p.auth(), &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "SUCCESS" {
+		return nil, fmt.Errorf("porkbun response status %s: %s", resp.Status, resp.Message)
+	}
+	return resp.Records, nil
+}
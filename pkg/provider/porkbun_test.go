@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+)
+
+func TestPorkbunDNS(t *testing.T) {
+	setupCache(t)
+	srv := fixtureServer(t, func(r *http.Request) string {
+		return r.Method + " " + r.URL.Path
+	}, map[string]string{
+		"POST /domain/listAll":           "porkbun/domain.listAll.json",
+		"POST /dns/retrieve/example.com": "porkbun/dns.retrieve.json",
+	})
+	account := public.Account{
+		CloudProvider: public.PorkbunDnsProvider,
+		CloudName:     "test",
+		SecretID:      "pk1_key",
+		SecretKey:     "sk1_secret",
+		Endpoint:      srv.URL,
+	}
+	p := &PorkbunDNS{account: account}
+
+	domains, err := p.ListDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 1 {
+		t.Fatalf("got %d domains, want 1", len(domains))
+	}
+	if d := domains[0]; d.DomainName != "example.com" || d.DomainStatus != "enable" || d.ExpiryDate != "2099-08-20 17:52:51" ||
+		d.Locked != "true" || d.AutoRenew != "false" {
+		t.Errorf("unexpected domain %+v", d)
+	}
+
+	cacheDomains(t, account, domains)
+	records, err := p.ListRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortRecords(records)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if r := records[0]; r.RecordID != "106926652" || r.RecordName != "@" || r.FullRecord != "example.com" || r.RecordTTL != "600" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if r := records[1]; r.RecordName != "www" || r.FullRecord != "www.example.com" || r.RecordTTL != "600" || r.RecordRemark != "web" {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
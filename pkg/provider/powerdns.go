@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/golang-module/carbon/v2"
+)
+
+// PowerDNS 通过 PowerDNS Authoritative 的 HTTP API 获取域与记录，支持配置多个服务器
+type PowerDNS struct {
+	account public.Account
+	servers []string                   // 按配置顺序排列的服务器地址
+	clients map[string]*restapi.Client // key 为服务器地址
+}
+
+type powerDNSZone struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	Kind    string          `json:"kind"`
+	Serial  int64           `json:"serial"`
+	DNSSEC  bool            `json:"dnssec"`
+	Account string          `json:"account"`
+	RRSets  []powerDNSRRSet `json:"rrsets"`
+}
+
+type powerDNSRRSet struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	TTL     int    `json:"ttl"`
+	Records []struct {
+		Content  string `json:"content"`
+		Disabled bool   `json:"disabled"`
+	} `json:"records"`
+	Comments []struct {
+		Content    string `json:"content"`
+		Account    string `json:"account"`
+		ModifiedAt int64  `json:"modified_at"`
+	} `json:"comments"`
+}
+
+// NewPowerDNSClient 初始化客户端
+func NewPowerDNSClient(endpoint, apiKey string) (*restapi.Client, error) {
+	return restapi.NewClient(strings.TrimSuffix(endpoint, "/"), restapi.WithHeader("X-API-Key", apiKey))
+}
+
+// NewPowerDNS 创建 PowerDNS 实例
+func NewPowerDNS(account public.Account) (*PowerDNS, error) {
+	servers := splitList(account.Options["servers"])
+	if len(servers) == 0 && account.Endpoint != "" {
+		servers = []string{account.Endpoint}
+	}
+	if len(servers) == 0 {
+		return nil, errors.New("missing powerdns servers")
+	}
+	clients := make(map[string]*restapi.Client)
+	for _, server := range servers {
+		client, err := NewPowerDNSClient(server, account.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+		clients[server] = client
+	}
+	return &PowerDNS{
+		account: account,
+		servers: servers,
+		clients: clients,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (p *PowerDNS) ListDomains() ([]Domain, error) {
+	pd, err := NewPowerDNS(p.account)
+	if err != nil {
+		return nil, err
+	}
+	p.servers, p.clients = pd.servers, pd.clients
+	var dataObj []Domain
+	// 多个服务器通常是同一批域的主备，同名的域只取配置中排在前面的服务器，避免输出重复的指标
+	seen := make(map[string]bool)
+	var (
+		succeeded int
+		lastErr   error
+	)
+	for _, server := range p.servers {
+		zones, err := p.getDomainList(p.clients[server])
+		if err != nil {
+			logger.Error(fmt.Sprintf("[ %s_%s ] get zone list from %s failed: %v", p.account.CloudProvider, p.account.CloudName, server, err))
+			lastErr = err
+			continue
+		}
+		succeeded++
+		for _, v := range zones {
+			name := strings.ToLower(strings.TrimSuffix(v.Name, "."))
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			dataObj = append(dataObj, Domain{
+				CloudProvider: p.account.CloudProvider,
+				CloudName:     p.account.CloudName,
+				DomainID:      v.ID,
+				DomainName:    name,
+				DomainRemark:  v.Account,
+				DomainStatus:  "enable",
+				SOASerial:     v.Serial,
+				Labels: map[string]string{
+					"server": server,
+					"kind":   v.Kind,
+					"dnssec": strconv.FormatBool(v.DNSSEC),
+				},
+			})
+		}
+	}
+	// 所有服务器都失败时返回错误，避免空列表覆盖上次成功获取的数据
+	if succeeded == 0 {
+		return nil, fmt.Errorf("get zone list from all %d servers failed: %w", len(p.servers), lastErr)
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (p *PowerDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	pd, err := NewPowerDNS(p.account)
+	if err != nil {
+		return nil, err
+	}
+	p.servers, p.clients = pd.servers, pd.clients
+	rst, err := public.Cache.Get(public.DomainList + "_" + p.account.CloudProvider + "_" + p.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	for _, domain := range domains {
+		client, ok := p.clients[domain.Labels["server"]]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(domain Domain, client *restapi.Client) {
+			defer wg.Done()
+			zone, err := p.getZone(client, domain.DomainID)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", p.account.CloudProvider, p.account.CloudName, err))
+				return
+			}
+			records := p.toRecords(domain, zone)
+			mu.Lock()
+			dataObj = append(dataObj, records...)
+			mu.Unlock()
+		}(domain, client)
+	}
+	wg.Wait()
+	return dataObj, nil
+}
+
+// toRecords 将 rrset 中的每条记录转换为 Record，rrset 的注释作为记录备注
+func (p *PowerDNS) toRecords(domain Domain, zone *powerDNSZone) (rst []Record) {
+	for _, rrset := range zone.RRSets {
+		name := strings.TrimSuffix(rrset.Name, ".")
+		if name == domain.DomainName {
+			name = "@"
+		} else {
+			name = strings.TrimSuffix(name, "."+domain.DomainName)
+		}
+		var (
+			remarks    []string
+			updateTime string
+		)
+		for _, comment := range rrset.Comments {
+			remarks = append(remarks, comment.Content)
+			if comment.ModifiedAt > 0 {
+				updateTime = carbon.CreateFromTimestamp(comment.ModifiedAt).ToDateTimeString()
+			}
+		}
+		for _, record := range rrset.Records {
+			status := "enable"
+			if record.Disabled {
+				status = "disable"
+			}
+			value := strings.TrimSuffix(record.Content, ".")
+			rst = append(rst, Record{
+				CloudProvider: p.account.CloudProvider,
+				CloudName:     p.account.CloudName,
+				DomainName:    domain.DomainName,
+				RecordID:      recordID(domain.DomainName, rrset.Type, name, value),
+				RecordType:    rrset.Type,
+				RecordName:    name,
+				RecordValue:   value,
+				RecordTTL:     strconv.Itoa(rrset.TTL),
+				RecordStatus:  status,
+				RecordRemark:  strings.Join(remarks, "; "),
+				UpdateTime:    updateTime,
+				FullRecord:    fullRecord(name, domain.DomainName),
+			})
+		}
+	}
+	return
+}
+
+// https://doc.powerdns.com/authoritative/http-api/zone.html#get--servers-server_id-zones
+// getDomainList 获取服务器上的域列表
+func (p *PowerDNS) getDomainList(client *restapi.Client) (rst []powerDNSZone, err error) {
+	err = client.Get("/api/v1/servers/"+p.serverID()+"/zones", nil, &rst)
+	return
+}
+
+// https://doc.powerdns.com/authoritative/http-api/zone.html#get--servers-server_id-zones-zone_id
+// getZone 获取域详情，其中包含全部 rrset，禁用的记录同样会返回
+func (p *PowerDNS) getZone(client *restapi.Client, zoneID string) (*powerDNSZone, error) {
+	var zone powerDNSZone
+	params := url.Values{}
+	params.Set("rrsets", "true")
+	if err := client.Get("/api/v1/servers/"+p.serverID()+"/zones/"+url.PathEscape(zoneID), params, &zone); err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// serverID PowerDNS 的 server_id，默认为 localhost
+func (p *PowerDNS) serverID() string {
+	if id := p.account.Options["serverId"]; id != "" {
+		return id
+	}
+	return "localhost"
+}
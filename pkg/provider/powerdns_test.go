@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+)
+
+func TestPowerDNSListDomainsServerFailure(t *testing.T) {
+	setupCache(t)
+	var failed []string
+	for i := 0; i < 2; i++ {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}))
+		t.Cleanup(srv.Close)
+		failed = append(failed, srv.URL)
+	}
+	ok := fixtureServer(t, func(r *http.Request) string {
+		return r.Method + " " + r.URL.Path
+	}, map[string]string{
+		"GET /api/v1/servers/localhost/zones": "powerdns/zones.json",
+	})
+	account := public.Account{
+		CloudProvider: public.PowerDnsProvider,
+		CloudName:     "test",
+		SecretKey:     "key",
+	}
+
+	// 所有服务器都失败时返回错误，而不是空列表
+	account.Options = map[string]string{"servers": failed[0] + "," + failed[1]}
+	p := &PowerDNS{account: account}
+	if domains, err := p.ListDomains(); err == nil {
+		t.Errorf("got %d domains without error, want error", len(domains))
+	}
+
+	// 部分服务器失败时使用其他服务器的结果
+	account.Options = map[string]string{"servers": failed[0] + "," + ok.URL}
+	p = &PowerDNS{account: account}
+	domains, err := p.ListDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 1 || domains[0].DomainName != "example.com" || domains[0].Labels["server"] != ok.URL {
+		t.Errorf("unexpected domains %+v", domains)
+	}
+}
@@ -1,8 +1,12 @@
 package provider
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/eryajf/cloud_dns_exporter/public"
 )
@@ -19,6 +23,8 @@ func init() {
 				CloudName:     account["name"],
 				SecretID:      account["secretId"],
 				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
 			},
 		}
 	})
@@ -39,6 +45,8 @@ func init() {
 				CloudName:     account["name"],
 				SecretID:      account["secretId"],
 				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
 			},
 		}
 	})
@@ -49,6 +57,7 @@ func init() {
 				CloudName:     account["name"],
 				SecretID:      account["secretId"],
 				SecretKey:     account["secretKey"],
+				Options:       account,
 			},
 		}
 	})
@@ -72,36 +81,235 @@ func init() {
 			},
 		}
 	})
+	Factory.Register(public.DigitalOceanDnsProvider, func(account map[string]string) DNSProvider {
+		return &DigitalOceanDNS{
+			account: public.Account{
+				CloudProvider: public.DigitalOceanDnsProvider,
+				CloudName:     account["name"],
+				SecretKey:     account["secretKey"],
+			},
+		}
+	})
+	Factory.Register(public.LinodeDnsProvider, func(account map[string]string) DNSProvider {
+		return &LinodeDNS{
+			account: public.Account{
+				CloudProvider: public.LinodeDnsProvider,
+				CloudName:     account["name"],
+				SecretKey:     account["secretKey"],
+			},
+		}
+	})
+	Factory.Register(public.VultrDnsProvider, func(account map[string]string) DNSProvider {
+		return &VultrDNS{
+			account: public.Account{
+				CloudProvider: public.VultrDnsProvider,
+				CloudName:     account["name"],
+				SecretKey:     account["secretKey"],
+			},
+		}
+	})
+	Factory.Register(public.HetznerDnsProvider, func(account map[string]string) DNSProvider {
+		return &HetznerDNS{
+			account: public.Account{
+				CloudProvider: public.HetznerDnsProvider,
+				CloudName:     account["name"],
+				SecretKey:     account["secretKey"],
+			},
+		}
+	})
+	Factory.Register(public.NamecheapDnsProvider, func(account map[string]string) DNSProvider {
+		return &NamecheapDNS{
+			account: public.Account{
+				CloudProvider: public.NamecheapDnsProvider,
+				CloudName:     account["name"],
+				SecretID:      account["secretId"],
+				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.PorkbunDnsProvider, func(account map[string]string) DNSProvider {
+		return &PorkbunDNS{
+			account: public.Account{
+				CloudProvider: public.PorkbunDnsProvider,
+				CloudName:     account["name"],
+				SecretID:      account["secretId"],
+				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.NameComDnsProvider, func(account map[string]string) DNSProvider {
+		return &NameComDNS{
+			account: public.Account{
+				CloudProvider: public.NameComDnsProvider,
+				CloudName:     account["name"],
+				SecretID:      account["secretId"],
+				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.GandiDnsProvider, func(account map[string]string) DNSProvider {
+		return &GandiDNS{
+			account: public.Account{
+				CloudProvider: public.GandiDnsProvider,
+				CloudName:     account["name"],
+				SecretID:      account["secretId"],
+				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.ZoneTransferDnsProvider, func(account map[string]string) DNSProvider {
+		return &ZoneTransferDNS{
+			account: public.Account{
+				CloudProvider: public.ZoneTransferDnsProvider,
+				CloudName:     account["name"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.PowerDnsProvider, func(account map[string]string) DNSProvider {
+		return &PowerDNS{
+			account: public.Account{
+				CloudProvider: public.PowerDnsProvider,
+				CloudName:     account["name"],
+				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.ZoneFileDnsProvider, func(account map[string]string) DNSProvider {
+		return &ZoneFileDNS{
+			account: public.Account{
+				CloudProvider: public.ZoneFileDnsProvider,
+				CloudName:     account["name"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.InfobloxDnsProvider, func(account map[string]string) DNSProvider {
+		return &InfobloxDNS{
+			account: public.Account{
+				CloudProvider: public.InfobloxDnsProvider,
+				CloudName:     account["name"],
+				SecretID:      account["secretId"],
+				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.TechnitiumDnsProvider, func(account map[string]string) DNSProvider {
+		return &TechnitiumDNS{
+			account: public.Account{
+				CloudProvider: public.TechnitiumDnsProvider,
+				CloudName:     account["name"],
+				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.AkamaiDnsProvider, func(account map[string]string) DNSProvider {
+		return &AkamaiDNS{
+			account: public.Account{
+				CloudProvider: public.AkamaiDnsProvider,
+				CloudName:     account["name"],
+				SecretID:      account["secretId"],
+				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.NS1DnsProvider, func(account map[string]string) DNSProvider {
+		return &NS1DNS{
+			account: public.Account{
+				CloudProvider: public.NS1DnsProvider,
+				CloudName:     account["name"],
+				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.UltraDnsProvider, func(account map[string]string) DNSProvider {
+		return &UltraDNS{
+			account: public.Account{
+				CloudProvider: public.UltraDnsProvider,
+				CloudName:     account["name"],
+				SecretID:      account["secretId"],
+				SecretKey:     account["secretKey"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.KubernetesDnsProvider, func(account map[string]string) DNSProvider {
+		return &KubernetesDNS{
+			account: public.Account{
+				CloudProvider: public.KubernetesDnsProvider,
+				CloudName:     account["name"],
+				Options:       account,
+			},
+		}
+	})
+	Factory.Register(public.GenericHTTPDnsProvider, func(account map[string]string) DNSProvider {
+		return &GenericHTTPDNS{
+			account: public.Account{
+				CloudProvider: public.GenericHTTPDnsProvider,
+				CloudName:     account["name"],
+				Endpoint:      account["endpoint"],
+				Options:       account,
+			},
+		}
+	})
 }
 
 // Doamin 域名信息
 type Domain struct {
-	CloudProvider   string `json:"cloud_provider"`
-	CloudName       string `json:"cloud_name"`
-	DomainID        string `json:"domain_id"`
-	DomainName      string `json:"domain_name"`
-	DomainRemark    string `json:"domain_remark"`
-	DomainStatus    string `json:"domain_status"`
-	CreatedDate     string `json:"created_date"`
-	ExpiryDate      string `json:"expiry_date"`
-	DaysUntilExpiry int64  `json:"days_until_expiry"`
+	CloudProvider   string            `json:"cloud_provider"`
+	CloudName       string            `json:"cloud_name"`
+	DomainID        string            `json:"domain_id"`
+	DomainName      string            `json:"domain_name"`
+	DomainRemark    string            `json:"domain_remark"`
+	DomainStatus    string            `json:"domain_status"`
+	CreatedDate     string            `json:"created_date"`
+	ExpiryDate      string            `json:"expiry_date"`
+	DaysUntilExpiry int64             `json:"days_until_expiry"`
+	AutoRenew       string            `json:"auto_renew"`       // 是否自动续费 true/false，未知时为空
+	Locked          string            `json:"locked"`           // 是否开启转移锁 true/false，未知时为空
+	SOASerial       int64             `json:"soa_serial"`       // SOA 序列号，仅自建权威服务器等可获取时有值
+	Labels          map[string]string `json:"labels,omitempty"` // 提供商特有的元数据，如 PowerDNS 的 kind、dnssec
 }
 
 // Record 域名记录信息
 type Record struct {
-	CloudProvider string `json:"cloud_provider"`
-	CloudName     string `json:"cloud_name"`
-	DomainName    string `json:"domain_name"`
-	RecordID      string `json:"record_id"`
-	RecordType    string `json:"record_type"`
-	RecordName    string `json:"record_name"`
-	RecordValue   string `json:"record_value"`
-	RecordTTL     string `json:"record_ttl"`
-	RecordWeight  string `json:"record_weight"`
-	RecordStatus  string `json:"record_status"`
-	RecordRemark  string `json:"record_remark"`
-	UpdateTime    string `json:"update_time"`
-	FullRecord    string `json:"full_record"` // 完整记录 = Name + Value
+	CloudProvider    string            `json:"cloud_provider"`
+	CloudName        string            `json:"cloud_name"`
+	DomainName       string            `json:"domain_name"`
+	RecordID         string            `json:"record_id"`
+	RecordType       string            `json:"record_type"`
+	RecordName       string            `json:"record_name"`
+	RecordValue      string            `json:"record_value"`
+	RecordTTL        string            `json:"record_ttl"`
+	RecordWeight     string            `json:"record_weight"`
+	RecordLine       string            `json:"record_line"`       // 解析线路，如 默认、电信
+	RecordLineID     string            `json:"record_line_id"`    // 解析线路ID
+	RecordPreference string            `json:"record_preference"` // MX 记录的优先级
+	RecordGroup      string            `json:"record_group"`      // 记录所属分组
+	RecordStatus     string            `json:"record_status"`
+	RecordRemark     string            `json:"record_remark"`
+	UpdateTime       string            `json:"update_time"`
+	FullRecord       string            `json:"full_record"`      // 完整记录 = Name + Value
+	Labels           map[string]string `json:"labels,omitempty"` // 提供商特有的元数据
 }
 
 type GetRecordCertReq struct {
@@ -111,6 +319,7 @@ type GetRecordCertReq struct {
 	FullRecord    string `json:"full_record"`
 	RecordValue   string `json:"record_value"`
 	RecordID      string `json:"record_id"`
+	CertSource    string `json:"cert_source"` // 证书来源，origin 为记录值指向的源站，代理记录为边缘节点
 }
 
 // RecordCert 域名证书信息
@@ -120,6 +329,7 @@ type RecordCert struct {
 	DomainName                string `json:"domain_name"`                 // 域名
 	FullRecord                string `json:"full_record"`                 // 完整记录 = Name + Value
 	RecordID                  string `json:"record_id"`                   // 记录ID
+	CertSource                string `json:"cert_source"`                 // 证书来源
 	SubjectCommonName         string `json:"subject_common_name"`         // 颁发对象的公用名
 	SubjectOrganization       string `json:"subject_organization"`        // 颁发对象的组织
 	SubjectOrganizationalUnit string `json:"subject_organizational_unit"` // 颁发对象的组织单位
@@ -129,6 +339,8 @@ type RecordCert struct {
 	CreatedDate               string `json:"created_date"`                // 创建日期
 	ExpiryDate                string `json:"expiry_date"`                 // 过期日期
 	DaysUntilExpiry           int    `json:"days_until_expiry"`           // 距离到期日期还有多少天
+	NotBefore                 int64  `json:"not_before"`                  // 证书生效时间，Unix 时间戳
+	NotAfter                  int64  `json:"not_after"`                   // 证书过期时间，Unix 时间戳
 	CertMatched               bool   `json:"cert_matched"`                // 证书是否匹配
 	ErrorMsg                  string `json:"error_msg"`
 }
@@ -172,3 +384,79 @@ func oneStatus(status string) string {
 	}
 	return status
 }
+
+// foldStatus 统一后接入的提供商的状态值，linode 等提供商返回的是小写的 active、disabled 或 verified
+// 已有的提供商继续使用 oneStatus，避免 cloudflare 的 active 等已有的 domain_status 取值发生变化
+func foldStatus(status string) string {
+	switch strings.ToUpper(status) {
+	case "ENABLE", "ACTIVE", "1", "VERIFIED":
+		return "enable"
+	case "DISABLE", "DISABLED":
+		return "disable"
+	}
+	return status
+}
+
+// recordName 统一主机记录，部分提供商使用空字符串表示根记录
+func recordName(name string) string {
+	if name == "" {
+		return "@"
+	}
+	return name
+}
+
+// accountEndpoint 账号配置了 endpoint 时优先使用，否则使用提供商默认的接口地址
+func accountEndpoint(account public.Account, defaultEndpoint string) string {
+	if account.Endpoint != "" {
+		return strings.TrimSuffix(account.Endpoint, "/")
+	}
+	return defaultEndpoint
+}
+
+// recordID 根据记录内容生成稳定的记录ID，用于接口未返回记录ID的提供商
+func recordID(domain, recordType, name, value string) string {
+	sum := sha1.Sum([]byte(strings.Join([]string{domain, recordType, name, value}, "|")))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// fullRecord 拼接完整记录，根记录即为域名本身
+func fullRecord(name, domain string) string {
+	if name == "" || name == "@" {
+		return domain
+	}
+	return name + "." + domain
+}
+
+// lookupCache 缓存解析线路、记录分组等变化较少的辅助数据，避免每轮刷新都重新请求
+type lookupCache[T any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]lookupEntry[T]
+}
+
+type lookupEntry[T any] struct {
+	value   T
+	expires time.Time
+}
+
+func newLookupCache[T any](ttl time.Duration) *lookupCache[T] {
+	return &lookupCache[T]{ttl: ttl, entries: make(map[string]lookupEntry[T])}
+}
+
+// get 返回未过期的缓存，否则调用 load 获取并缓存，load 失败时不缓存
+func (c *lookupCache[T]) get(key string, load func() (T, error)) (T, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.value, nil
+	}
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	c.mu.Lock()
+	c.entries[key] = lookupEntry[T]{value: value, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return value, nil
+}
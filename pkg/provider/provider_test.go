@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+// setupCache 初始化内存缓存，ListRecords 从缓存中读取 ListDomains 的结果
+func setupCache(t *testing.T) {
+	t.Helper()
+	logger.InitLogger("info")
+	cache, err := public.NewStore(public.StorageConfig{}, "test", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public.Cache = cache
+}
+
+// cacheDomains 按定时任务的方式将域名列表写入缓存
+func cacheDomains(t *testing.T, account public.Account, domains []Domain) {
+	t.Helper()
+	value, err := json.Marshal(domains)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := public.Cache.Set(public.DomainList+"_"+account.CloudProvider+"_"+account.CloudName, value); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fixtureServer 按 route 返回 testdata 下录制的响应，route 根据请求计算用于匹配的键
+func fixtureServer(t *testing.T, route func(r *http.Request) string, fixtures map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := fixtures[route(r)]
+		if !ok {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if filepath.Ext(name) == ".xml" {
+			w.Header().Set("Content-Type", "application/xml")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// sortRecords 按完整记录、类型与值排序，便于断言
+func sortRecords(records []Record) {
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.FullRecord != b.FullRecord {
+			return a.FullRecord < b.FullRecord
+		}
+		if a.RecordType != b.RecordType {
+			return a.RecordType < b.RecordType
+		}
+		return a.RecordValue < b.RecordValue
+	})
+}
+
+func TestLookupCache(t *testing.T) {
+	c := newLookupCache[int](time.Hour)
+	calls := 0
+	load := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+	for i := 0; i < 2; i++ {
+		if v, err := c.get("a", load); err != nil || v != 1 {
+			t.Fatalf("got %d, %v, want 1", v, err)
+		}
+	}
+	if v, _ := c.get("b", load); v != 2 {
+		t.Errorf("got %d for another key, want 2", v)
+	}
+
+	// 失败的结果不缓存
+	if _, err := c.get("c", func() (int, error) { return 0, errors.New("failed") }); err == nil {
+		t.Error("want error")
+	}
+	if v, _ := c.get("c", load); v != 3 {
+		t.Errorf("got %d after a failed load, want 3", v)
+	}
+
+	// 过期后重新获取
+	c.ttl = -time.Second
+	if v, _ := c.get("d", load); v != 4 {
+		t.Fatalf("got %d, want 4", v)
+	}
+	if v, _ := c.get("d", load); v != 5 {
+		t.Errorf("got %d after expiry, want 5", v)
+	}
+}
@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/golang-module/carbon/v2"
+)
+
+// TechnitiumDNS 通过 Technitium DNS Server 的 HTTP API 获取域与记录
+type TechnitiumDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+type technitiumZone struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Internal     bool   `json:"internal"`
+	DnssecStatus string `json:"dnssecStatus"`
+	SoaSerial    int64  `json:"soaSerial"`
+	Disabled     bool   `json:"disabled"`
+	LastModified string `json:"lastModified"`
+}
+
+type technitiumRecord struct {
+	Name         string                     `json:"name"`
+	Type         string                     `json:"type"`
+	TTL          int                        `json:"ttl"`
+	Disabled     bool                       `json:"disabled"`
+	Comments     string                     `json:"comments"`
+	LastModified string                     `json:"lastModified"`
+	RData        map[string]json.RawMessage `json:"rData"`
+}
+
+// technitiumRData 常见记录类型的记录值字段，未列出的类型使用全部字段拼接
+var technitiumRData = map[string][]string{
+	"A":     {"ipAddress"},
+	"AAAA":  {"ipAddress"},
+	"CNAME": {"cname"},
+	"ANAME": {"aname"},
+	"NS":    {"nameServer"},
+	"PTR":   {"ptrName"},
+	"MX":    {"preference", "exchange"},
+	"TXT":   {"text"},
+	"SRV":   {"priority", "weight", "port", "target"},
+	"CAA":   {"flags", "tag", "value"},
+}
+
+// NewTechnitiumClient 初始化客户端，token 以查询参数的方式传递
+func NewTechnitiumClient(endpoint, token string) (*restapi.Client, error) {
+	return restapi.NewClient(strings.TrimSuffix(endpoint, "/"), restapi.WithQueryParam("token", token))
+}
+
+// NewTechnitiumDNS 创建 TechnitiumDNS 实例
+func NewTechnitiumDNS(account public.Account) (*TechnitiumDNS, error) {
+	if account.Endpoint == "" {
+		return nil, errors.New("missing technitium endpoint")
+	}
+	client, err := NewTechnitiumClient(account.Endpoint, account.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &TechnitiumDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表，仅返回 Primary 类型的权威域
+func (t *TechnitiumDNS) ListDomains() ([]Domain, error) {
+	td, err := NewTechnitiumDNS(t.account)
+	if err != nil {
+		return nil, err
+	}
+	t.client = td.client
+	var dataObj []Domain
+	zones, err := t.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range zones {
+		if v.Internal || v.Type != "Primary" {
+			continue
+		}
+		status := "enable"
+		if v.Disabled {
+			status = "disable"
+		}
+		dataObj = append(dataObj, Domain{
+			CloudProvider: t.account.CloudProvider,
+			CloudName:     t.account.CloudName,
+			DomainID:      v.Name,
+			DomainName:    v.Name,
+			DomainStatus:  status,
+			SOASerial:     v.SoaSerial,
+			Labels: map[string]string{
+				"kind":   v.Type,
+				"dnssec": v.DnssecStatus,
+			},
+		})
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (t *TechnitiumDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	td, err := NewTechnitiumDNS(t.account)
+	if err != nil {
+		return nil, err
+	}
+	t.client = td.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + t.account.CloudProvider + "_" + t.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]technitiumRecord)
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			records, err := t.getRecordList(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", t.account.CloudProvider, t.account.CloudName, err))
+			}
+			mu.Lock()
+			results[domain] = records
+			mu.Unlock()
+		}(domain.DomainName)
+	}
+	wg.Wait()
+	for domain, records := range results {
+		for _, v := range records {
+			if v.Type == "SOA" {
+				continue
+			}
+			name := v.Name
+			if name == domain {
+				name = "@"
+			} else {
+				name = strings.TrimSuffix(name, "."+domain)
+			}
+			status := "enable"
+			if v.Disabled {
+				status = "disable"
+			}
+			value := technitiumValue(v)
+			updateTime := ""
+			if v.LastModified != "" {
+				updateTime = carbon.Parse(v.LastModified).ToDateTimeString()
+			}
+			dataObj = append(dataObj, Record{
+				CloudProvider: t.account.CloudProvider,
+				CloudName:     t.account.CloudName,
+				DomainName:    domain,
+				RecordID:      recordID(domain, v.Type, name, value),
+				RecordType:    v.Type,
+				RecordName:    name,
+				RecordValue:   value,
+				RecordTTL:     strconv.Itoa(v.TTL),
+				RecordStatus:  status,
+				RecordRemark:  v.Comments,
+				UpdateTime:    updateTime,
+				FullRecord:    v.Name,
+			})
+		}
+	}
+	return dataObj, nil
+}
+
+// https://github.com/TechnitiumSoftware/DnsServer/blob/master/APIDOCS.md#list-zones
+// getDomainList 获取域列表
+func (t *TechnitiumDNS) getDomainList() ([]technitiumZone, error) {
+	var resp struct {
+		Status       string `json:"status"`
+		ErrorMessage string `json:"errorMessage"`
+		Response     struct {
+			Zones []technitiumZone `json:"zones"`
+		} `json:"response"`
+	}
+	if err := t.client.Get("/api/zones/list", nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "ok" {
+		return nil, fmt.Errorf("technitium response status %s: %s", resp.Status, resp.ErrorMessage)
+	}
+	return resp.Response.Zones, nil
+}
+
+// https://github.com/TechnitiumSoftware/DnsServer/blob/master/APIDOCS.md#get-records
+// getRecordList 获取域中的全部记录
+func (t *TechnitiumDNS) getRecordList(domain string) ([]technitiumRecord, error) {
+	var resp struct {
+		Status       string `json:"status"`
+		ErrorMessage string `json:"errorMessage"`
+		Response     struct {
+			Records []technitiumRecord `json:"records"`
+		} `json:"response"`
+	}
+	params := url.Values{}
+	params.Set("domain", domain)
+	params.Set("zone", domain)
+	params.Set("listZone", "true")
+	if err := t.client.Get("/api/zones/records/get", params, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "ok" {
+		return nil, fmt.Errorf("technitium response status %s: %s", resp.Status, resp.ErrorMessage)
+	}
+	return resp.Response.Records, nil
+}
+
+// technitiumValue 根据记录类型拼接记录值
+func technitiumValue(record technitiumRecord) string {
+	fields, ok := technitiumRData[record.Type]
+	if !ok {
+		for field := range record.RData {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+	}
+	var values []string
+	for _, field := range fields {
+		var value interface{}
+		if err := json.Unmarshal(record.RData[field], &value); err != nil {
+			continue
+		}
+		values = append(values, fmt.Sprint(value))
+	}
+	return strings.TrimSuffix(strings.Join(values, " "), ".")
+}
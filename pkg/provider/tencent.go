@@ -23,12 +23,54 @@ type TencentCloudDNS struct {
 	client  *dnspod.Client
 }
 
-// NewTencentClient 初始化客户端
-func NewTencentClient(secretID, secretKey string) (*dnspod.Client, error) {
-	credential := common.NewCredential(secretID, secretKey)
+// tencentIntlEndpoints 国际站(intl.cloud.tencent.com)的接口地址
+var tencentIntlEndpoints = map[string]string{
+	"dnspod": "dnspod.intl.tencentcloudapi.com",
+	"domain": "domain.intl.tencentcloudapi.com",
+}
+
+// NewTencentCredential 获取账号凭证，未配置密钥时使用默认凭证链(环境变量、配置文件、CVM 角色)
+// 配置了 roleArn 时通过 STS AssumeRole 获取临时凭证，返回的 RoleArnCredential 持有 RoleArnProvider，
+// 临时凭证到期前 SDK 在取用时会通过它重新 AssumeRole，无需重建客户端
+func NewTencentCredential(account public.Account) (common.CredentialIface, error) {
+	var credential common.CredentialIface
+	switch {
+	case account.Options["roleArn"] != "":
+		sessionName := account.Options["roleSessionName"]
+		if sessionName == "" {
+			sessionName = "cloud_dns_exporter"
+		}
+		return common.NewRoleArnProvider(account.SecretID, account.SecretKey, account.Options["roleArn"], sessionName, 7200).GetCredential()
+	case account.SecretID != "" && account.Options["sessionToken"] != "":
+		credential = common.NewTokenCredential(account.SecretID, account.SecretKey, account.Options["sessionToken"])
+	case account.SecretID != "":
+		credential = common.NewCredential(account.SecretID, account.SecretKey)
+	default:
+		return common.DefaultProviderChain().GetCredential()
+	}
+	return credential, nil
+}
+
+// tencentProfile 获取接口配置，endpoint 优先，其次根据 site 选择国内站或国际站
+func tencentProfile(account public.Account, service string) *profile.ClientProfile {
 	cpf := profile.NewClientProfile()
-	cpf.HttpProfile.Endpoint = "dnspod.tencentcloudapi.com"
-	client, err := dnspod.NewClient(credential, "", cpf)
+	cpf.HttpProfile.Endpoint = service + ".tencentcloudapi.com"
+	if account.Options["site"] == "intl" {
+		cpf.HttpProfile.Endpoint = tencentIntlEndpoints[service]
+	}
+	if service == "dnspod" && account.Endpoint != "" {
+		cpf.HttpProfile.Endpoint = account.Endpoint
+	}
+	return cpf
+}
+
+// NewTencentClient 初始化客户端
+func NewTencentClient(account public.Account) (*dnspod.Client, error) {
+	credential, err := NewTencentCredential(account)
+	if err != nil {
+		return nil, err
+	}
+	client, err := dnspod.NewClient(credential, account.Options["region"], tencentProfile(account, "dnspod"))
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +79,7 @@ func NewTencentClient(secretID, secretKey string) (*dnspod.Client, error) {
 
 // NewTencentCloudDNS 创建 TencentCloudDNS 实例
 func NewTencentCloudDNS(account public.Account) (*TencentCloudDNS, error) {
-	client, err := NewTencentClient(account.SecretID, account.SecretKey)
+	client, err := NewTencentClient(account)
 	if err != nil {
 		return nil, err
 	}
@@ -49,12 +91,7 @@ func NewTencentCloudDNS(account public.Account) (*TencentCloudDNS, error) {
 
 // ListDomains 获取域名列表
 func (t *TencentCloudDNS) ListDomains() ([]Domain, error) {
-	tcd, err := NewTencentCloudDNS(public.Account{
-		CloudProvider: t.account.CloudProvider,
-		CloudName:     t.account.CloudName,
-		SecretID:      t.account.SecretID,
-		SecretKey:     t.account.SecretKey,
-	})
+	tcd, err := NewTencentCloudDNS(t.account)
 	if err != nil {
 		return nil, err
 	}
@@ -94,12 +131,7 @@ func (t *TencentCloudDNS) ListRecords() ([]Record, error) {
 		wg      sync.WaitGroup
 		mu      sync.Mutex
 	)
-	tcd, err := NewTencentCloudDNS(public.Account{
-		CloudProvider: t.account.CloudProvider,
-		CloudName:     t.account.CloudName,
-		SecretID:      t.account.SecretID,
-		SecretKey:     t.account.SecretKey,
-	})
+	tcd, err := NewTencentCloudDNS(t.account)
 	if err != nil {
 		return nil, err
 	}
@@ -113,6 +145,7 @@ func (t *TencentCloudDNS) ListRecords() ([]Record, error) {
 		return nil, err
 	}
 	results := make(map[string][]*dnspod.RecordListItem)
+	groups := make(map[string]map[uint64]string)
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 	for _, domain := range domains {
@@ -120,32 +153,45 @@ func (t *TencentCloudDNS) ListRecords() ([]Record, error) {
 		go func(domain string) {
 			defer wg.Done()
 			<-ticker.C
-			records, err := t.getRecordList(domain)
+			records, err := t.getRecordList(domain, nil)
 			if err != nil {
 				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", t.account.CloudProvider, t.account.CloudName, err))
 			}
+			recordGroups, err := t.getRecordGroups(domain)
+			if err != nil {
+				logger.Warning(fmt.Sprintf("[ %s_%s ] get record groups of %s failed: %v", t.account.CloudProvider, t.account.CloudName, domain, err))
+			}
 			mu.Lock()
 			results[domain] = records
+			groups[domain] = recordGroups
 			mu.Unlock()
 		}(domain.DomainName)
 	}
 	wg.Wait()
 	for domain, records := range results {
 		for _, v := range records {
+			var preference string
+			if tea.StringValue(v.Type) == "MX" {
+				preference = fmt.Sprintf("%d", tea.Uint64Value(v.MX))
+			}
 			dataObj = append(dataObj, Record{
-				CloudProvider: t.account.CloudProvider,
-				CloudName:     t.account.CloudName,
-				DomainName:    domain,
-				RecordID:      fmt.Sprintf("%d", tea.Uint64Value(v.RecordId)),
-				RecordType:    tea.StringValue(v.Type),
-				RecordName:    tea.StringValue(v.Name),
-				RecordValue:   tea.StringValue(v.Value),
-				RecordTTL:     fmt.Sprintf("%d", tea.Uint64Value(v.TTL)),
-				RecordWeight:  fmt.Sprintf("%d", tea.Uint64Value(v.Weight)),
-				RecordStatus:  oneStatus(tea.StringValue(v.Status)),
-				RecordRemark:  tea.StringValue(v.Remark),
-				UpdateTime:    tea.StringValue(v.UpdatedOn),
-				FullRecord:    tea.StringValue(v.Name) + "." + domain,
+				CloudProvider:    t.account.CloudProvider,
+				CloudName:        t.account.CloudName,
+				DomainName:       domain,
+				RecordID:         fmt.Sprintf("%d", tea.Uint64Value(v.RecordId)),
+				RecordType:       tea.StringValue(v.Type),
+				RecordName:       tea.StringValue(v.Name),
+				RecordValue:      tea.StringValue(v.Value),
+				RecordTTL:        fmt.Sprintf("%d", tea.Uint64Value(v.TTL)),
+				RecordWeight:     fmt.Sprintf("%d", tea.Uint64Value(v.Weight)),
+				RecordLine:       tea.StringValue(v.Line),
+				RecordLineID:     tea.StringValue(v.LineId),
+				RecordPreference: preference,
+				RecordGroup:      groups[domain][tea.Uint64Value(v.RecordId)],
+				RecordStatus:     oneStatus(tea.StringValue(v.Status)),
+				RecordRemark:     tea.StringValue(v.Remark),
+				UpdateTime:       tea.StringValue(v.UpdatedOn),
+				FullRecord:       tea.StringValue(v.Name) + "." + domain,
 			})
 		}
 	}
@@ -155,20 +201,35 @@ func (t *TencentCloudDNS) ListRecords() ([]Record, error) {
 // https://cloud.tencent.com/document/api/1427/56172
 // GetDomainList 获取云解析中域名列表
 func (t *TencentCloudDNS) getDomainList() ([]*dnspod.DomainListItem, error) {
+	var (
+		offset int64 = 0
+		limit  int64 = 3000
+		temp   []*dnspod.DomainListItem
+	)
 	request := dnspod.NewDescribeDomainListRequest()
-	response, err := t.client.DescribeDomainList(request)
-	if _, ok := err.(*errors.TencentCloudSDKError); ok {
-		return nil, err
-	}
-	if err != nil {
-		return nil, err
+	for {
+		request.Offset = common.Int64Ptr(offset)
+		request.Limit = common.Int64Ptr(limit)
+		response, err := t.client.DescribeDomainList(request)
+		if err != nil {
+			return nil, err
+		}
+		temp = append(temp, response.Response.DomainList...)
+		total := len(response.Response.DomainList)
+		if response.Response.DomainCountInfo != nil {
+			total = int(tea.Uint64Value(response.Response.DomainCountInfo.AllTotal))
+		}
+		if len(response.Response.DomainList) < int(limit) || len(temp) >= total {
+			break
+		}
+		offset += limit
 	}
-	return response.Response.DomainList, nil
+	return temp, nil
 }
 
 // https://cloud.tencent.com/document/api/1427/56166
 // RecordList 域名记录列表
-func (t *TencentCloudDNS) getRecordList(domain string) ([]*dnspod.RecordListItem, error) {
+func (t *TencentCloudDNS) getRecordList(domain string, groupId *uint64) ([]*dnspod.RecordListItem, error) {
 	var (
 		offset uint64 = 0
 		limit  uint64 = 3000
@@ -176,6 +237,7 @@ func (t *TencentCloudDNS) getRecordList(domain string) ([]*dnspod.RecordListItem
 	)
 	request := dnspod.NewDescribeRecordListRequest()
 	request.Domain = common.StringPtr(domain)
+	request.GroupId = groupId
 	for {
 		request.Offset = common.Uint64Ptr(offset)
 		request.Limit = common.Uint64Ptr(limit)
@@ -197,6 +259,43 @@ func (t *TencentCloudDNS) getRecordList(domain string) ([]*dnspod.RecordListItem
 	return temp, nil
 }
 
+// https://cloud.tencent.com/document/api/1427/80510
+// tencentRecordGroups 缓存各域名的记录分组，获取分组成员需要按分组重新列出记录
+var tencentRecordGroups = newLookupCache[map[uint64]string](time.Hour)
+
+// getRecordGroups 获取记录所属的自定义分组，返回记录ID到分组名称的映射，结果缓存 1 小时
+func (t *TencentCloudDNS) getRecordGroups(domain string) (map[uint64]string, error) {
+	key := t.account.CloudProvider + "_" + t.account.CloudName + "_" + domain
+	return tencentRecordGroups.get(key, func() (map[uint64]string, error) {
+		return t.describeRecordGroups(domain)
+	})
+}
+
+// describeRecordGroups 按自定义分组列出记录，未使用分组的域名不会产生额外的请求
+func (t *TencentCloudDNS) describeRecordGroups(domain string) (map[uint64]string, error) {
+	request := dnspod.NewDescribeRecordGroupListRequest()
+	request.Domain = common.StringPtr(domain)
+	request.Limit = common.Uint64Ptr(3000)
+	response, err := t.client.DescribeRecordGroupList(request)
+	if err != nil {
+		return nil, err
+	}
+	rst := make(map[uint64]string)
+	for _, group := range response.Response.GroupList {
+		if tea.StringValue(group.GroupType) != "user" {
+			continue
+		}
+		records, err := t.getRecordList(domain, group.GroupId)
+		if err != nil {
+			return rst, err
+		}
+		for _, v := range records {
+			rst[tea.Uint64Value(v.RecordId)] = tea.StringValue(group.GroupName)
+		}
+	}
+	return rst, nil
+}
+
 // https://cloud.tencent.com/document/api/242/48941
 // getDomainNameList 获取域名列表(与云解析的域名列表注意区分)
 func (t *TencentCloudDNS) getDomainNameList() ([]*domain.DomainList, error) {
@@ -205,12 +304,14 @@ func (t *TencentCloudDNS) getDomainNameList() ([]*domain.DomainList, error) {
 		limit  uint64 = 100
 		temp   []*domain.DomainList
 	)
-	cpf := profile.NewClientProfile()
-	cpf.HttpProfile.Endpoint = "domain.tencentcloudapi.com"
-	client, _ := domain.NewClient(common.NewCredential(
-		t.account.SecretID,
-		t.account.SecretKey,
-	), "", cpf)
+	credential, err := NewTencentCredential(t.account)
+	if err != nil {
+		return nil, err
+	}
+	client, err := domain.NewClient(credential, "", tencentProfile(t.account, "domain"))
+	if err != nil {
+		return nil, err
+	}
 
 	request := domain.NewDescribeDomainNameListRequest()
 	for {
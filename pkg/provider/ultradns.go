@@ -0,0 +1,289 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+// UltraDNS 通过 UltraDNS REST API 获取域与记录，使用 OAuth Token 认证，资源池中的每个 rdata 拆分为一条记录
+type UltraDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+type ultraDNSResultInfo struct {
+	TotalCount    int `json:"totalCount"`
+	Offset        int `json:"offset"`
+	ReturnedCount int `json:"returnedCount"`
+}
+
+type ultraDNSZone struct {
+	Properties struct {
+		Name                 string `json:"name"`
+		AccountName          string `json:"accountName"`
+		Type                 string `json:"type"`
+		DnssecStatus         string `json:"dnssecStatus"`
+		Status               string `json:"status"`
+		LastModifiedDateTime string `json:"lastModifiedDateTime"`
+	} `json:"properties"`
+}
+
+type ultraDNSRRSet struct {
+	OwnerName string                     `json:"ownerName"`
+	RRType    string                     `json:"rrtype"`
+	TTL       int                        `json:"ttl"`
+	Rdata     []string                   `json:"rdata"`
+	Profile   map[string]json.RawMessage `json:"profile"`
+}
+
+// NewUltraDNSClient 使用用户名密码换取 OAuth Token 后初始化客户端
+func NewUltraDNSClient(endpoint, username, password string) (*restapi.Client, error) {
+	auth, err := restapi.NewClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "password")
+	form.Set("username", username)
+	form.Set("password", password)
+	var token struct {
+		AccessToken string `json:"accessToken"`
+	}
+	if err := auth.PostForm("/v2/authorization/token", form, &token); err != nil {
+		return nil, fmt.Errorf("get ultradns token failed: %w", err)
+	}
+	return restapi.NewClient(endpoint, restapi.WithBearerToken(token.AccessToken))
+}
+
+// NewUltraDNS 创建 UltraDNS 实例
+func NewUltraDNS(account public.Account) (*UltraDNS, error) {
+	client, err := NewUltraDNSClient(accountEndpoint(account, "https://api.ultradns.com"), account.SecretID, account.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &UltraDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (u *UltraDNS) ListDomains() ([]Domain, error) {
+	ud, err := NewUltraDNS(u.account)
+	if err != nil {
+		return nil, err
+	}
+	u.client = ud.client
+	var dataObj []Domain
+	zones, err := u.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range zones {
+		name := strings.TrimSuffix(v.Properties.Name, ".")
+		dataObj = append(dataObj, Domain{
+			CloudProvider: u.account.CloudProvider,
+			CloudName:     u.account.CloudName,
+			DomainID:      name,
+			DomainName:    name,
+			DomainRemark:  v.Properties.AccountName,
+			DomainStatus:  foldStatus(v.Properties.Status),
+			Labels: map[string]string{
+				"kind":   v.Properties.Type,
+				"dnssec": v.Properties.DnssecStatus,
+			},
+		})
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (u *UltraDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	ud, err := NewUltraDNS(u.account)
+	if err != nil {
+		return nil, err
+	}
+	u.client = ud.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + u.account.CloudProvider + "_" + u.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]ultraDNSRRSet)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			<-ticker.C
+			records, err := u.getRecordList(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", u.account.CloudProvider, u.account.CloudName, err))
+			}
+			mu.Lock()
+			results[domain] = records
+			mu.Unlock()
+		}(domain.DomainName)
+	}
+	wg.Wait()
+	for domain, rrsets := range results {
+		for _, v := range rrsets {
+			dataObj = append(dataObj, u.toRecords(domain, v)...)
+		}
+	}
+	return dataObj, nil
+}
+
+// toRecords 将 rrset 按 rdata 拆分，资源池(RD/SB/TC/Dir Pool)的配置保存在 Labels 中
+func (u *UltraDNS) toRecords(domain string, rrset ultraDNSRRSet) (rst []Record) {
+	// rrtype 的格式为 "A (1)"
+	fields := strings.Fields(rrset.RRType)
+	if len(fields) == 0 {
+		return nil
+	}
+	recordType := fields[0]
+	fqdn := strings.TrimSuffix(rrset.OwnerName, ".")
+	name := fqdn
+	if name == domain {
+		name = "@"
+	} else {
+		name = strings.TrimSuffix(name, "."+domain)
+	}
+	var (
+		poolLabels = make(map[string]string)
+		rdataInfo  []map[string]interface{}
+	)
+	if ctx, ok := rrset.Profile["@context"]; ok {
+		var context string
+		_ = json.Unmarshal(ctx, &context)
+		poolLabels["pool_type"] = strings.TrimSuffix(path.Base(context), ".jsonschema")
+		for k, raw := range rrset.Profile {
+			if k == "@context" || k == "rdataInfo" {
+				continue
+			}
+			var value interface{}
+			if err := json.Unmarshal(raw, &value); err != nil {
+				continue
+			}
+			if _, isObject := value.(map[string]interface{}); isObject {
+				continue
+			}
+			if _, isList := value.([]interface{}); isList {
+				continue
+			}
+			poolLabels["pool_"+k] = fmt.Sprint(value)
+		}
+		_ = json.Unmarshal(rrset.Profile["rdataInfo"], &rdataInfo)
+	}
+	for i, rdata := range rrset.Rdata {
+		value := strings.TrimSuffix(rdata, ".")
+		record := Record{
+			CloudProvider: u.account.CloudProvider,
+			CloudName:     u.account.CloudName,
+			DomainName:    domain,
+			RecordID:      recordID(domain, recordType, name, value),
+			RecordType:    recordType,
+			RecordName:    name,
+			RecordValue:   value,
+			RecordTTL:     strconv.Itoa(rrset.TTL),
+			RecordStatus:  "enable",
+			FullRecord:    fqdn,
+		}
+		if len(poolLabels) > 0 {
+			record.Labels = make(map[string]string)
+			for k, v := range poolLabels {
+				record.Labels[k] = v
+			}
+		}
+		if i < len(rdataInfo) {
+			for k, v := range rdataInfo[i] {
+				switch info := v.(type) {
+				case map[string]interface{}:
+					// DirPool 的 geoInfo、ipInfo 只保留分组名称
+					if n, ok := info["name"]; ok {
+						record.Labels[k] = fmt.Sprint(n)
+					}
+				case []interface{}:
+				default:
+					record.Labels["info_"+k] = fmt.Sprint(info)
+				}
+			}
+			record.RecordWeight = record.Labels["info_weight"]
+			if state := record.Labels["info_state"]; state != "" && state != "NORMAL" && state != "ACTIVE" {
+				record.RecordStatus = "disable"
+			}
+		}
+		rst = append(rst, record)
+	}
+	return
+}
+
+// https://docs.ultradns.com/Content/REST%20API/Content/REST%20API/Zone%20API/Zone%20API.htm
+// getDomainList 获取域列表
+func (u *UltraDNS) getDomainList() (rst []ultraDNSZone, err error) {
+	offset := 0
+	for {
+		var resp struct {
+			ResultInfo ultraDNSResultInfo `json:"resultInfo"`
+			Zones      []ultraDNSZone     `json:"zones"`
+		}
+		if err := u.client.Get("/v2/zones", ultraDNSPage(offset), &resp); err != nil {
+			return nil, err
+		}
+		rst = append(rst, resp.Zones...)
+		offset += resp.ResultInfo.ReturnedCount
+		if resp.ResultInfo.ReturnedCount == 0 || offset >= resp.ResultInfo.TotalCount {
+			break
+		}
+	}
+	return
+}
+
+// https://docs.ultradns.com/Content/REST%20API/Content/REST%20API/Resource%20Record%20API/Resource%20Record%20API.htm
+// getRecordList 获取记录集列表
+func (u *UltraDNS) getRecordList(domain string) (rst []ultraDNSRRSet, err error) {
+	offset := 0
+	for {
+		var resp struct {
+			ResultInfo ultraDNSResultInfo `json:"resultInfo"`
+			RRSets     []ultraDNSRRSet    `json:"rrSets"`
+		}
+		if err := u.client.Get("/v2/zones/"+domain+"/rrsets", ultraDNSPage(offset), &resp); err != nil {
+			return rst, err
+		}
+		rst = append(rst, resp.RRSets...)
+		offset += resp.ResultInfo.ReturnedCount
+		if resp.ResultInfo.ReturnedCount == 0 || offset >= resp.ResultInfo.TotalCount {
+			break
+		}
+	}
+	return
+}
+
+// ultraDNSPage 分页参数
+func ultraDNSPage(offset int) url.Values {
+	params := url.Values{}
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("limit", "1000")
+	return params
+}
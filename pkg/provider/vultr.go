@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/dnslib/restapi"
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+)
+
+type VultrDNS struct {
+	account public.Account
+	client  *restapi.Client
+}
+
+type vultrMeta struct {
+	Total int `json:"total"`
+	Links struct {
+		Next string `json:"next"`
+		Prev string `json:"prev"`
+	} `json:"links"`
+}
+
+type vultrDomain struct {
+	Domain      string `json:"domain"`
+	DateCreated string `json:"date_created"`
+	DNSSec      string `json:"dns_sec"`
+}
+
+type vultrRecord struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Data     string `json:"data"`
+	Priority int    `json:"priority"`
+	TTL      int    `json:"ttl"`
+}
+
+// NewVultrClient 初始化客户端
+func NewVultrClient(token string) (*restapi.Client, error) {
+	return restapi.NewClient("https://api.vultr.com", restapi.WithBearerToken(token))
+}
+
+// NewVultrDNS 创建 VultrDNS 实例
+func NewVultrDNS(account public.Account) (*VultrDNS, error) {
+	client, err := NewVultrClient(account.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &VultrDNS{
+		account: account,
+		client:  client,
+	}, nil
+}
+
+// ListDomains 获取域名列表
+func (v *VultrDNS) ListDomains() ([]Domain, error) {
+	vd, err := NewVultrDNS(v.account)
+	if err != nil {
+		return nil, err
+	}
+	v.client = vd.client
+	var dataObj []Domain
+	domains, err := v.getDomainList()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range domains {
+		dataObj = append(dataObj, Domain{
+			CloudProvider: v.account.CloudProvider,
+			CloudName:     v.account.CloudName,
+			DomainID:      d.Domain,
+			DomainName:    d.Domain,
+			DomainStatus:  "enable",
+			CreatedDate:   d.DateCreated,
+		})
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (v *VultrDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	vd, err := NewVultrDNS(v.account)
+	if err != nil {
+		return nil, err
+	}
+	v.client = vd.client
+	rst, err := public.Cache.Get(public.DomainList + "_" + v.account.CloudProvider + "_" + v.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]vultrRecord)
+	// vultr 接口限制每秒 30 次请求
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			<-ticker.C
+			records, err := v.getRecordList(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] get record list failed: %v", v.account.CloudProvider, v.account.CloudName, err))
+			}
+			mu.Lock()
+			results[domain] = records
+			mu.Unlock()
+		}(domain.DomainName)
+	}
+	wg.Wait()
+	for domain, records := range results {
+		for _, r := range records {
+			dataObj = append(dataObj, Record{
+				CloudProvider: v.account.CloudProvider,
+				CloudName:     v.account.CloudName,
+				DomainName:    domain,
+				RecordID:      r.ID,
+				RecordType:    r.Type,
+				RecordName:    recordName(r.Name),
+				RecordValue:   r.Data,
+				RecordTTL:     strconv.Itoa(r.TTL),
+				RecordStatus:  "enable",
+				FullRecord:    fullRecord(r.Name, domain),
+			})
+		}
+	}
+	return dataObj, nil
+}
+
+// https://www.vultr.com/api/#tag/dns/operation/list-dns-domains
+// getDomainList 获取域名列表，使用游标分页
+func (v *VultrDNS) getDomainList() (rst []vultrDomain, err error) {
+	cursor := ""
+	for {
+		var resp struct {
+			Domains []vultrDomain `json:"domains"`
+			Meta    vultrMeta     `json:"meta"`
+		}
+		params := url.Values{}
+		params.Set("per_page", "500")
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+		if err := v.client.Get("/v2/domains", params, &resp); err != nil {
+			return nil, err
+		}
+		rst = append(rst, resp.Domains...)
+		if resp.Meta.Links.Next == "" {
+			break
+		}
+		cursor = resp.Meta.Links.Next
+	}
+	return
+}
+
+// https://www.vultr.com/api/#tag/dns/operation/list-dns-domain-records
+// getRecordList 获取记录列表，使用游标分页
+func (v *VultrDNS) getRecordList(domain string) (rst []vultrRecord, err error) {
+	cursor := ""
+	for {
+		var resp struct {
+			Records []vultrRecord `json:"records"`
+			Meta    vultrMeta     `json:"meta"`
+		}
+		params := url.Values{}
+		params.Set("per_page", "500")
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+		if err := v.client.Get("/v2/domains/"+domain+"/records", params, &resp); err != nil {
+			return rst, err
+		}
+		rst = append(rst, resp.Records...)
+		if resp.Meta.Links.Next == "" {
+			break
+		}
+		cursor = resp.Meta.Links.Next
+	}
+	return
+}
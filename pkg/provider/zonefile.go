@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/miekg/dns"
+)
+
+// ZoneFileDNS 解析本地 RFC 1035 格式的区域文件，适用于使用 git 管理的域
+type ZoneFileDNS struct {
+	account public.Account
+	files   []string
+}
+
+// NewZoneFileDNS 创建 ZoneFileDNS 实例，files 支持逗号分隔的多个文件或 glob 表达式
+func NewZoneFileDNS(account public.Account) (*ZoneFileDNS, error) {
+	patterns := splitList(account.Options["files"])
+	if len(patterns) == 0 {
+		return nil, errors.New("missing zonefile files")
+	}
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	// 被其他区域文件 $INCLUDE 的片段同样可能匹配 glob，这些文件不是独立的域
+	included := includedFiles(files)
+	var zoneFiles []string
+	for _, file := range files {
+		if !included[absPath(file)] {
+			zoneFiles = append(zoneFiles, file)
+		}
+	}
+	files = zoneFiles
+	return &ZoneFileDNS{
+		account: account,
+		files:   files,
+	}, nil
+}
+
+// ListDomains 获取域名列表，每个包含 SOA 的区域文件即为一个域
+func (z *ZoneFileDNS) ListDomains() ([]Domain, error) {
+	zfd, err := NewZoneFileDNS(z.account)
+	if err != nil {
+		return nil, err
+	}
+	z.files = zfd.files
+	var dataObj []Domain
+	for _, file := range z.files {
+		zone, soa, _, err := parseZoneFile(file)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[ %s_%s ] parse zone file %s failed: %v", z.account.CloudProvider, z.account.CloudName, file, err))
+			continue
+		}
+		d := Domain{
+			CloudProvider: z.account.CloudProvider,
+			CloudName:     z.account.CloudName,
+			DomainID:      zone,
+			DomainName:    zone,
+			DomainStatus:  "enable",
+			SOASerial:     int64(soa.Serial),
+			Labels: map[string]string{
+				"file": file,
+			},
+		}
+		dataObj = append(dataObj, d)
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (z *ZoneFileDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+	)
+	rst, err := public.Cache.Get(public.DomainList + "_" + z.account.CloudProvider + "_" + z.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	for _, domain := range domains {
+		_, _, rrs, err := parseZoneFile(domain.Labels["file"])
+		if err != nil {
+			logger.Error(fmt.Sprintf("[ %s_%s ] parse zone file %s failed: %v", z.account.CloudProvider, z.account.CloudName, domain.Labels["file"], err))
+			continue
+		}
+		for _, rr := range rrs {
+			if _, ok := rr.(*dns.SOA); ok {
+				continue
+			}
+			dataObj = append(dataObj, rrToRecord(z.account, domain.DomainName, rr))
+		}
+	}
+	return dataObj, nil
+}
+
+// parseZoneFile 解析区域文件，支持 $ORIGIN、$TTL、$INCLUDE 指令
+// 文件中未声明 $ORIGIN 时，根据文件名推断初始的 origin，如 db.example.com、example.com.zone
+func parseZoneFile(file string) (zone string, soa *dns.SOA, rrs []dns.RR, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	defer f.Close()
+	zp := dns.NewZoneParser(f, dns.Fqdn(originFromFileName(file)), file)
+	zp.SetIncludeAllowed(true)
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		if s, isSOA := rr.(*dns.SOA); isSOA && soa == nil {
+			soa = s
+		}
+		rrs = append(rrs, rr)
+	}
+	if err := zp.Err(); err != nil {
+		return "", nil, nil, err
+	}
+	if soa == nil {
+		return "", nil, nil, errors.New("no soa record found")
+	}
+	zone = strings.TrimSuffix(strings.ToLower(soa.Hdr.Name), ".")
+	return zone, soa, rrs, nil
+}
+
+// includedFiles 返回区域文件中通过 $INCLUDE 引用的文件(包括嵌套引用)的绝对路径
+// 相对路径相对于引用它的文件所在的目录，与 dns.ZoneParser 的处理一致
+func includedFiles(files []string) map[string]bool {
+	included := make(map[string]bool)
+	pending := append([]string(nil), files...)
+	scanned := make(map[string]bool)
+	for len(pending) > 0 {
+		file := absPath(pending[0])
+		pending = pending[1:]
+		if scanned[file] {
+			continue
+		}
+		scanned[file] = true
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 2 || !strings.EqualFold(fields[0], "$INCLUDE") {
+				continue
+			}
+			include := fields[1]
+			if !filepath.IsAbs(include) {
+				include = filepath.Join(filepath.Dir(file), include)
+			}
+			included[absPath(include)] = true
+			pending = append(pending, include)
+		}
+		f.Close()
+	}
+	return included
+}
+
+func absPath(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return filepath.Clean(file)
+}
+
+// originFromFileName 根据区域文件名推断域名
+func originFromFileName(file string) string {
+	name := filepath.Base(file)
+	name = strings.TrimPrefix(name, "db.")
+	for _, suffix := range []string{".zone", ".db", ".hosts", ".txt"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return name
+}
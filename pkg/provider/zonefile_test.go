@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+)
+
+func TestZoneFileDNS(t *testing.T) {
+	setupCache(t)
+	account := public.Account{
+		CloudProvider: public.ZoneFileDnsProvider,
+		CloudName:     "test",
+		// common.zone 匹配 glob，但它被 example.com.zone 引用，不应作为独立的域
+		Options: map[string]string{"files": "testdata/zonefile/*.zone,testdata/zonefile/db.example.net"},
+	}
+	z, err := NewZoneFileDNS(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range z.files {
+		if filepath.Base(file) == "common.zone" {
+			t.Errorf("included file %s is listed as a zone file", file)
+		}
+	}
+	if len(z.files) != 2 {
+		t.Errorf("got zone files %v, want 2", z.files)
+	}
+
+	domains, err := z.ListDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].DomainName < domains[j].DomainName })
+	if len(domains) != 2 {
+		t.Fatalf("got %d domains, want 2: %+v", len(domains), domains)
+	}
+	if d := domains[0]; d.DomainName != "example.com" || d.SOASerial != 2024010101 {
+		t.Errorf("unexpected domain %+v", d)
+	}
+	if d := domains[1]; d.DomainName != "example.net" || d.SOASerial != 7 {
+		t.Errorf("unexpected domain %+v", d)
+	}
+
+	cacheDomains(t, account, domains)
+	records, err := z.ListRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]Record)
+	for _, r := range records {
+		got[r.DomainName+" "+r.FullRecord+" "+r.RecordType] = r
+	}
+	want := map[string]struct{ name, value, ttl string }{
+		"example.com example.com NS":            {"@", "ns1.example.com", "3600"},
+		"example.com example.com MX":            {"@", "10 mail.example.com", "3600"},
+		"example.com www.example.com A":         {"www", "192.0.2.10", "300"},
+		"example.com ns1.example.com A":         {"ns1", "192.0.2.53", "3600"},
+		"example.com mail.example.com A":        {"mail", "192.0.2.25", "3600"},
+		"example.com api.dev.example.com CNAME": {"api.dev", "www.example.com", "3600"},
+		"example.net example.net NS":            {"@", "ns1.example.net", "600"},
+		"example.net ns1.example.net A":         {"ns1", "198.51.100.53", "600"},
+	}
+	if len(records) != len(want) {
+		t.Errorf("got %d records, want %d: %+v", len(records), len(want), records)
+	}
+	for key, w := range want {
+		r, ok := got[key]
+		if !ok {
+			t.Errorf("missing record %s", key)
+			continue
+		}
+		if r.RecordName != w.name || r.RecordValue != w.value || r.RecordTTL != w.ttl {
+			t.Errorf("record %s: got name %q value %q ttl %q, want %+v", key, r.RecordName, r.RecordValue, r.RecordTTL, w)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/eryajf/cloud_dns_exporter/public/logger"
+	"github.com/miekg/dns"
+)
+
+// ZoneTransferDNS 通过 AXFR/IXFR 从自建的权威服务器(BIND/Knot/PowerDNS 等)拉取解析记录
+type ZoneTransferDNS struct {
+	account public.Account
+	zones   []string
+	servers []string
+}
+
+// zoneTransferSnapshot 记录每个域上一次传输的结果，用于后续发起 IXFR 增量传输
+type zoneTransferSnapshot struct {
+	soa     *dns.SOA
+	records []dns.RR
+}
+
+var (
+	zoneTransferMu        sync.Mutex
+	zoneTransferSnapshots = make(map[string]*zoneTransferSnapshot)
+)
+
+// NewZoneTransferDNS 创建 ZoneTransferDNS 实例
+func NewZoneTransferDNS(account public.Account) (*ZoneTransferDNS, error) {
+	zones := splitList(account.Options["zones"])
+	if len(zones) == 0 {
+		return nil, errors.New("missing zonetransfer zones")
+	}
+	servers := splitList(account.Options["servers"])
+	if len(servers) == 0 {
+		return nil, errors.New("missing zonetransfer servers")
+	}
+	for i, server := range servers {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			servers[i] = net.JoinHostPort(server, "53")
+		}
+	}
+	for i, zone := range zones {
+		zones[i] = strings.TrimSuffix(strings.ToLower(zone), ".")
+	}
+	return &ZoneTransferDNS{
+		account: account,
+		zones:   zones,
+		servers: servers,
+	}, nil
+}
+
+// ListDomains 获取域名列表，域名即配置中的 zones，同时查询 SOA 序列号
+func (z *ZoneTransferDNS) ListDomains() ([]Domain, error) {
+	ztd, err := NewZoneTransferDNS(z.account)
+	if err != nil {
+		return nil, err
+	}
+	z.zones, z.servers = ztd.zones, ztd.servers
+	var dataObj []Domain
+	for _, zone := range z.zones {
+		d := Domain{
+			CloudProvider: z.account.CloudProvider,
+			CloudName:     z.account.CloudName,
+			DomainID:      zone,
+			DomainName:    zone,
+			DomainStatus:  "enable",
+		}
+		soa, err := z.getSOA(zone)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[ %s_%s ] get %s soa failed: %v", z.account.CloudProvider, z.account.CloudName, zone, err))
+			d.DomainStatus = "disable"
+		} else {
+			d.SOASerial = int64(soa.Serial)
+		}
+		dataObj = append(dataObj, d)
+	}
+	return dataObj, nil
+}
+
+// ListRecords 获取记录列表
+func (z *ZoneTransferDNS) ListRecords() ([]Record, error) {
+	var (
+		dataObj []Record
+		domains []Domain
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+	ztd, err := NewZoneTransferDNS(z.account)
+	if err != nil {
+		return nil, err
+	}
+	z.zones, z.servers = ztd.zones, ztd.servers
+	rst, err := public.Cache.Get(public.DomainList + "_" + z.account.CloudProvider + "_" + z.account.CloudName)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rst, &domains)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]dns.RR)
+	for _, domain := range domains {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			records, err := z.transfer(domain)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[ %s_%s ] transfer %s failed: %v", z.account.CloudProvider, z.account.CloudName, domain, err))
+				return
+			}
+			mu.Lock()
+			results[domain] = records
+			mu.Unlock()
+		}(domain.DomainName)
+	}
+	wg.Wait()
+	for domain, records := range results {
+		for _, rr := range records {
+			dataObj = append(dataObj, rrToRecord(z.account, domain, rr))
+		}
+	}
+	return dataObj, nil
+}
+
+// transfer 拉取域的全部记录，已有快照时优先使用 IXFR，失败时回退为 AXFR
+func (z *ZoneTransferDNS) transfer(zone string) ([]dns.RR, error) {
+	key := z.account.CloudName + "_" + zone
+	zoneTransferMu.Lock()
+	snapshot := zoneTransferSnapshots[key]
+	zoneTransferMu.Unlock()
+
+	var (
+		next *zoneTransferSnapshot
+		err  error
+	)
+	if snapshot != nil {
+		next, err = z.ixfr(zone, snapshot)
+		if err != nil {
+			logger.Warning(fmt.Sprintf("[ %s_%s ] ixfr %s failed, fallback to axfr: %v", z.account.CloudProvider, z.account.CloudName, zone, err))
+		}
+	}
+	if next == nil {
+		next, err = z.axfr(zone)
+		if err != nil {
+			return nil, err
+		}
+	}
+	zoneTransferMu.Lock()
+	zoneTransferSnapshots[key] = next
+	zoneTransferMu.Unlock()
+	return next.records, nil
+}
+
+// axfr 发起全量传输
+func (z *ZoneTransferDNS) axfr(zone string) (*zoneTransferSnapshot, error) {
+	m := new(dns.Msg)
+	m.SetAxfr(dns.Fqdn(zone))
+	rrs, err := z.exchange(m)
+	if err != nil {
+		return nil, err
+	}
+	return newZoneTransferSnapshot(rrs)
+}
+
+// ixfr 发起增量传输，并将差异应用到上一次的快照上
+// https://datatracker.ietf.org/doc/html/rfc1995#section-4
+func (z *ZoneTransferDNS) ixfr(zone string, snapshot *zoneTransferSnapshot) (*zoneTransferSnapshot, error) {
+	m := new(dns.Msg)
+	m.SetIxfr(dns.Fqdn(zone), snapshot.soa.Serial, snapshot.soa.Ns, snapshot.soa.Mbox)
+	rrs, err := z.exchange(m)
+	if err != nil {
+		return nil, err
+	}
+	if len(rrs) == 0 {
+		return nil, errors.New("empty ixfr response")
+	}
+	soa, ok := rrs[0].(*dns.SOA)
+	if !ok {
+		return nil, errors.New("ixfr response does not start with soa")
+	}
+	// 只返回一条 SOA 说明没有变更
+	if len(rrs) == 1 || soa.Serial == snapshot.soa.Serial {
+		return snapshot, nil
+	}
+	// 第二条不是 SOA 说明服务器返回的是全量数据
+	if _, ok := rrs[1].(*dns.SOA); !ok {
+		return newZoneTransferSnapshot(rrs)
+	}
+	records := append([]dns.RR(nil), snapshot.records...)
+	deleting := false
+	for _, rr := range rrs[1 : len(rrs)-1] {
+		if _, ok := rr.(*dns.SOA); ok {
+			deleting = !deleting
+			continue
+		}
+		if deleting {
+			for i, old := range records {
+				if dns.IsDuplicate(old, rr) {
+					records = append(records[:i], records[i+1:]...)
+					break
+				}
+			}
+		} else {
+			records = append(records, rr)
+		}
+	}
+	return &zoneTransferSnapshot{soa: soa, records: records}, nil
+}
+
+// exchange 依次尝试配置的服务器，返回第一个成功传输的结果
+func (z *ZoneTransferDNS) exchange(m *dns.Msg) (rrs []dns.RR, err error) {
+	for _, server := range z.servers {
+		rrs, err = z.exchangeWith(m, server)
+		if err == nil {
+			return rrs, nil
+		}
+	}
+	return nil, err
+}
+
+func (z *ZoneTransferDNS) exchangeWith(m *dns.Msg, server string) ([]dns.RR, error) {
+	t := &dns.Transfer{DialTimeout: 3 * time.Second, ReadTimeout: 30 * time.Second}
+	if tsigName, tsigSecret := z.account.Options["tsigName"], z.account.Options["tsigSecret"]; tsigName != "" && tsigSecret != "" {
+		algorithm := z.account.Options["tsigAlgorithm"]
+		if algorithm == "" {
+			algorithm = dns.HmacSHA256
+		}
+		t.TsigSecret = map[string]string{dns.Fqdn(tsigName): tsigSecret}
+		// 每次尝试使用新的消息，避免重试其他服务器时重复附加 TSIG 记录
+		m = m.Copy()
+		m.SetTsig(dns.Fqdn(tsigName), dns.Fqdn(algorithm), 300, time.Now().Unix())
+	}
+	envelopes, err := t.In(m, server)
+	if err != nil {
+		return nil, err
+	}
+	var rrs []dns.RR
+	for e := range envelopes {
+		if e.Error != nil {
+			return nil, e.Error
+		}
+		rrs = append(rrs, e.RR...)
+	}
+	return rrs, nil
+}
+
+// getSOA 查询域的 SOA 记录
+func (z *ZoneTransferDNS) getSOA(zone string) (soa *dns.SOA, err error) {
+	c := &dns.Client{Timeout: 3 * time.Second}
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(zone), dns.TypeSOA)
+	for _, server := range z.servers {
+		var resp *dns.Msg
+		resp, _, err = c.Exchange(m, server)
+		if err != nil {
+			continue
+		}
+		for _, rr := range resp.Answer {
+			if soa, ok := rr.(*dns.SOA); ok {
+				return soa, nil
+			}
+		}
+		err = fmt.Errorf("no soa record in response from %s", server)
+	}
+	return nil, err
+}
+
+// newZoneTransferSnapshot 将全量传输的结果转换为快照，首尾的 SOA 不计入记录
+func newZoneTransferSnapshot(rrs []dns.RR) (*zoneTransferSnapshot, error) {
+	if len(rrs) == 0 {
+		return nil, errors.New("empty transfer response")
+	}
+	soa, ok := rrs[0].(*dns.SOA)
+	if !ok {
+		return nil, errors.New("transfer response does not start with soa")
+	}
+	snapshot := &zoneTransferSnapshot{soa: soa}
+	for _, rr := range rrs[1:] {
+		if _, ok := rr.(*dns.SOA); ok {
+			continue
+		}
+		snapshot.records = append(snapshot.records, rr)
+	}
+	return snapshot, nil
+}
+
+// rrToRecord 将标准的 DNS 资源记录转换为 Record
+func rrToRecord(account public.Account, zone string, rr dns.RR) Record {
+	hdr := rr.Header()
+	name := strings.TrimSuffix(strings.ToLower(hdr.Name), ".")
+	if name == zone {
+		name = "@"
+	} else {
+		name = strings.TrimSuffix(name, "."+zone)
+	}
+	recordType := dns.TypeToString[hdr.Rrtype]
+	value := strings.TrimSpace(strings.TrimPrefix(rr.String(), hdr.String()))
+	// CNAME/NS/MX 等记录的值为完整域名，去掉末尾的点与云厂商保持一致
+	value = strings.TrimSuffix(value, ".")
+	return Record{
+		CloudProvider: account.CloudProvider,
+		CloudName:     account.CloudName,
+		DomainName:    zone,
+		RecordID:      recordID(zone, recordType, name, value),
+		RecordType:    recordType,
+		RecordName:    name,
+		RecordValue:   value,
+		RecordTTL:     fmt.Sprintf("%d", hdr.Ttl),
+		RecordStatus:  "enable",
+		FullRecord:    fullRecord(name, zone),
+	}
+}
+
+// splitList 拆分以逗号分隔的配置项
+func splitList(s string) (rst []string) {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			rst = append(rst, v)
+		}
+	}
+	return
+}
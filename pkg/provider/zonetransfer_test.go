@@ -0,0 +1,230 @@
+package provider
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/eryajf/cloud_dns_exporter/public"
+	"github.com/miekg/dns"
+)
+
+const (
+	testTsigName   = "transfer."
+	testTsigSecret = "c2VjcmV0LXNlY3JldC1zZWNyZXQ="
+)
+
+// testZoneServer 进程内的权威服务器，区传送要求 TSIG，IXFR 按 RFC 1995 返回与上一版本的差异
+type testZoneServer struct {
+	mu      sync.Mutex
+	serial  uint32
+	records map[uint32][]dns.RR
+	queries []uint16
+}
+
+func testSOA(serial uint32) dns.RR {
+	rr, _ := dns.NewRR("example.test. 3600 IN SOA ns1.example.test. admin.example.test. " + strconv.FormatUint(uint64(serial), 10) + " 3600 600 86400 300")
+	return rr
+}
+
+func mustRRs(t *testing.T, lines ...string) (rrs []dns.RR) {
+	t.Helper()
+	for _, line := range lines {
+		rr, err := dns.NewRR(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rrs = append(rrs, rr)
+	}
+	return
+}
+
+func (s *testZoneServer) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := r.Question[0]
+	s.queries = append(s.queries, q.Qtype)
+	if q.Qtype == dns.TypeSOA {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		m.Answer = []dns.RR{testSOA(s.serial)}
+		_ = w.WriteMsg(m)
+		return
+	}
+	if r.IsTsig() == nil || w.TsigStatus() != nil {
+		m := new(dns.Msg)
+		m.SetRcode(r, dns.RcodeRefused)
+		_ = w.WriteMsg(m)
+		return
+	}
+	current := append([]dns.RR{testSOA(s.serial)}, s.records[s.serial]...)
+	current = append(current, testSOA(s.serial))
+	rrs := current
+	if q.Qtype == dns.TypeIXFR {
+		from := r.Ns[0].(*dns.SOA).Serial
+		if from == s.serial {
+			rrs = []dns.RR{testSOA(s.serial)}
+		} else if old, ok := s.records[from]; ok {
+			rrs = []dns.RR{testSOA(s.serial), testSOA(from)}
+			rrs = append(rrs, diffRRs(old, s.records[s.serial])...)
+			rrs = append(rrs, testSOA(s.serial))
+			rrs = append(rrs, diffRRs(s.records[s.serial], old)...)
+			rrs = append(rrs, testSOA(s.serial))
+		}
+	}
+	ch := make(chan *dns.Envelope)
+	tr := &dns.Transfer{TsigSecret: map[string]string{testTsigName: testTsigSecret}}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = tr.Out(w, r, ch)
+	}()
+	ch <- &dns.Envelope{RR: rrs}
+	close(ch)
+	wg.Wait()
+	w.Hijack()
+}
+
+// diffRRs 返回 a 中有而 b 中没有的记录
+func diffRRs(a, b []dns.RR) (rst []dns.RR) {
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if dns.IsDuplicate(x, y) {
+				found = true
+			}
+		}
+		if !found {
+			rst = append(rst, x)
+		}
+	}
+	return
+}
+
+// startTestZoneServer 在同一端口上启动 TCP 与 UDP 服务
+func startTestZoneServer(t *testing.T, handler dns.Handler) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, err := net.ListenPacket("udp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := map[string]string{testTsigName: testTsigSecret}
+	for _, srv := range []*dns.Server{
+		{Listener: l, Handler: handler, TsigSecret: secret},
+		{PacketConn: pc, Handler: handler, TsigSecret: secret},
+	} {
+		srv := srv
+		go func() { _ = srv.ActivateAndServe() }()
+		t.Cleanup(func() { _ = srv.Shutdown() })
+	}
+	return l.Addr().String()
+}
+
+// unusedAddr 返回一个没有服务监听的地址，用于模拟不可用的服务器
+func unusedAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+func TestZoneTransferDNS(t *testing.T) {
+	setupCache(t)
+	server := &testZoneServer{
+		serial: 1,
+		records: map[uint32][]dns.RR{
+			1: mustRRs(t,
+				"example.test. 3600 IN NS ns1.example.test.",
+				"www.example.test. 300 IN A 192.0.2.1",
+				"mail.example.test. 300 IN MX 10 mx.example.test.",
+			),
+			2: mustRRs(t,
+				"example.test. 3600 IN NS ns1.example.test.",
+				"www.example.test. 300 IN A 192.0.2.2",
+				"mail.example.test. 300 IN MX 10 mx.example.test.",
+				"api.example.test. 60 IN CNAME www.example.test.",
+			),
+		},
+	}
+	addr := startTestZoneServer(t, server)
+	account := public.Account{
+		CloudProvider: public.ZoneTransferDnsProvider,
+		CloudName:     "test_" + t.Name(),
+		Options: map[string]string{
+			"zones": "Example.Test.",
+			// 第一台服务器不可用，重试第二台时消息中只能有一条 TSIG 记录
+			"servers":    unusedAddr(t) + "," + addr,
+			"tsigName":   "transfer",
+			"tsigSecret": testTsigSecret,
+		},
+	}
+	z := &ZoneTransferDNS{account: account}
+
+	domains, err := z.ListDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 1 || domains[0].DomainName != "example.test" || domains[0].SOASerial != 1 || domains[0].DomainStatus != "enable" {
+		t.Fatalf("unexpected domains %+v", domains)
+	}
+	cacheDomains(t, account, domains)
+
+	records, err := z.ListRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortRecords(records)
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3: %+v", len(records), records)
+	}
+	if r := records[0]; r.FullRecord != "example.test" || r.RecordName != "@" || r.RecordType != "NS" || r.RecordValue != "ns1.example.test" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if r := records[1]; r.FullRecord != "mail.example.test" || r.RecordValue != "10 mx.example.test" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if r := records[2]; r.FullRecord != "www.example.test" || r.RecordValue != "192.0.2.1" || r.RecordTTL != "300" {
+		t.Errorf("unexpected record %+v", r)
+	}
+
+	// 序列号变化后使用 IXFR 增量传输
+	server.mu.Lock()
+	server.serial = 2
+	server.queries = nil
+	server.mu.Unlock()
+	records, err = z.ListRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortRecords(records)
+	server.mu.Lock()
+	queries := server.queries
+	server.mu.Unlock()
+	if len(queries) != 1 || queries[0] != dns.TypeIXFR {
+		t.Errorf("got queries %v, want a single IXFR", queries)
+	}
+	var values []string
+	for _, r := range records {
+		values = append(values, r.FullRecord+" "+r.RecordValue)
+	}
+	want := []string{"api.example.test www.example.test", "example.test ns1.example.test", "mail.example.test 10 mx.example.test", "www.example.test 192.0.2.2"}
+	if len(values) != len(want) {
+		t.Fatalf("got records %v, want %v", values, want)
+	}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Errorf("got records %v, want %v", values, want)
+			break
+		}
+	}
+}
@@ -1,12 +1,10 @@
 package public
 
 import (
-	"context"
 	"os"
 	"sync"
 	"time"
 
-	"github.com/allegro/bigcache/v3"
 	"github.com/eryajf/cloud_dns_exporter/public/logger"
 	"github.com/rs/xid"
 
@@ -23,35 +21,199 @@ const (
 	// Custom
 	CustomRecords string = "custom_records"
 	// Cloud Providers
-	TencentDnsProvider    string = "tencent"
-	AliyunDnsProvider     string = "aliyun"
-	GodaddyDnsProvider    string = "godaddy"
-	DNSLaDnsProvider      string = "dnsla"
-	AmazonDnsProvider     string = "amazon"
-	CloudFlareDnsProvider string = "cloudflare"
+	TencentDnsProvider      string = "tencent"
+	AliyunDnsProvider       string = "aliyun"
+	GodaddyDnsProvider      string = "godaddy"
+	DNSLaDnsProvider        string = "dnsla"
+	AmazonDnsProvider       string = "amazon"
+	CloudFlareDnsProvider   string = "cloudflare"
+	DigitalOceanDnsProvider string = "digitalocean"
+	LinodeDnsProvider       string = "linode"
+	VultrDnsProvider        string = "vultr"
+	HetznerDnsProvider      string = "hetzner"
+	NamecheapDnsProvider    string = "namecheap"
+	PorkbunDnsProvider      string = "porkbun"
+	NameComDnsProvider      string = "namecom"
+	GandiDnsProvider        string = "gandi"
+	ZoneTransferDnsProvider string = "zonetransfer"
+	PowerDnsProvider        string = "powerdns"
+	ZoneFileDnsProvider     string = "zonefile"
+	InfobloxDnsProvider     string = "infoblox"
+	TechnitiumDnsProvider   string = "technitium"
+	AkamaiDnsProvider       string = "akamai"
+	NS1DnsProvider          string = "ns1"
+	UltraDnsProvider        string = "ultradns"
+	KubernetesDnsProvider   string = "kubernetes"
+	GenericHTTPDnsProvider  string = "generic_http"
 	// Metrics Name
-	DomainList     string = "domain_list"
-	RecordList     string = "record_list"
-	RecordCertInfo string = "record_cert_info"
+	DomainList      string = "domain_list"
+	RecordList      string = "record_list"
+	RecordCertInfo  string = "record_cert_info"
+	DomainSOASerial string = "domain_soa_serial"
+	DomainLabelInfo string = "domain_label_info"
+	RecordLabelInfo string = "record_label_info"
+	// Metrics Name (v2 schema)
+	DomainInfo                    string = "domain_info"
+	DomainExpiryTimestampSeconds  string = "domain_expiry_timestamp_seconds"
+	DomainCreatedTimestampSeconds string = "domain_created_timestamp_seconds"
+	RecordInfo                    string = "record_info"
+	RecordTTLSeconds              string = "record_ttl_seconds"
+	RecordUpdatedTimestampSeconds string = "record_updated_timestamp_seconds"
+	ZoneRecordCount               string = "zone_record_count"
+	CertInfo                      string = "cert_info"
+	CertNotBeforeTimestampSeconds string = "cert_not_before_timestamp_seconds"
+	CertNotAfterTimestampSeconds  string = "cert_not_after_timestamp_seconds"
+	CertMatched                   string = "cert_matched"
+	DataAgeSeconds                string = "data_age_seconds"
+	DataStale                     string = "data_stale"
+	RecordChangesTotal            string = "record_changes_total"
+	// RecordSnapshot 缓存中用于对比记录变更的上一次记录快照的键前缀
+	RecordSnapshot string = "record_snapshot"
+	// DataUpdatedAt 缓存中记录域名及记录列表最近一次成功获取时间的键前缀
+	DataUpdatedAt string = "data_updated_at"
+	// Metrics Schema
+	MetricsSchemaLegacy string = "legacy" // 原有的 *_list 指标，取值变化会产生新的时间序列
+	MetricsSchemaV2     string = "v2"     // 低基数的 *_info 指标加数值指标
+	MetricsSchemaBoth   string = "both"   // 同时输出两套指标，用于迁移期间
 )
 
 var (
 	once      sync.Once
 	Config    *Configuration
-	Cache     *bigcache.BigCache
-	CertCache *bigcache.BigCache
+	Cache     Store
+	CertCache Store
 )
 
 type Account struct {
-	CloudProvider string `yaml:"cloud_provider"`
-	CloudName     string `yaml:"cloud_name"`
-	SecretID      string `yaml:"secretId"`
-	SecretKey     string `yaml:"secretKey"`
+	CloudProvider string            `yaml:"cloud_provider"`
+	CloudName     string            `yaml:"cloud_name"`
+	SecretID      string            `yaml:"secretId"`
+	SecretKey     string            `yaml:"secretKey"`
+	Endpoint      string            `yaml:"endpoint"` // 自定义接口地址，用于沙箱环境或本地录制的接口
+	Options       map[string]string `yaml:"options"`  // 账号的原始配置，用于读取各提供商特有的字段
+}
+
+// Plugin 外部 DNS 提供商插件，通过标准输入输出以 JSON 通信
+type Plugin struct {
+	Command string            `yaml:"command"` // 插件可执行文件路径
+	Args    []string          `yaml:"args"`    // 可选，启动参数
+	Env     map[string]string `yaml:"env"`     // 可选，额外的环境变量
+	Timeout string            `yaml:"timeout"` // 可选，单次调用的超时时间，默认 60s
+}
+
+// LabelConfig 指标标签的配置，用于附加静态标签、裁剪内置标签及按规则重写标签
+type LabelConfig struct {
+	Include []string        `yaml:"include"` // 可选，保留的内置标签，为空时保留全部
+	Exclude []string        `yaml:"exclude"` // 可选，去掉的内置标签，如 record_remark
+	Static  []StaticLabel   `yaml:"static"`  // 可选，按账号或域名附加的静态标签
+	Relabel []RelabelConfig `yaml:"relabel"` // 可选，输出指标前依次执行的重写规则
+}
+
+// StaticLabel 匹配条件均为可选，全部满足时附加 Labels
+type StaticLabel struct {
+	CloudProvider string            `yaml:"cloud_provider"`
+	CloudName     string            `yaml:"cloud_name"`
+	DomainRegex   string            `yaml:"domain_regex"`
+	Labels        map[string]string `yaml:"labels"`
+}
+
+// RelabelConfig 与 Prometheus metric_relabel_configs 语义一致，支持 replace、keep、drop
+type RelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels"`
+	Separator    string   `yaml:"separator"`    // 默认为 ;
+	Regex        string   `yaml:"regex"`        // 默认为 (.*)
+	TargetLabel  string   `yaml:"target_label"` // action 为 replace 时必填
+	Replacement  string   `yaml:"replacement"`  // 默认为 $1
+	Action       string   `yaml:"action"`       // 默认为 replace
+}
+
+// ProbeModule /probe 接口的探测模块
+type ProbeModule struct {
+	Prober     string `yaml:"prober"`      // tls(默认) 或 dns
+	Timeout    string `yaml:"timeout"`     // 可选，默认 10s
+	Port       string `yaml:"port"`        // tls，target 未指定端口时使用，默认 443
+	ServerName string `yaml:"server_name"` // tls，可选，SNI 及证书匹配使用的域名，默认为 target 的主机名
+	StartTLS   string `yaml:"starttls"`    // tls，可选，smtp 表示通过 STARTTLS 升级
+	QueryType  string `yaml:"query_type"`  // dns，查询的记录类型，默认 A
+	Resolver   string `yaml:"resolver"`    // dns，可选，默认使用 /etc/resolv.conf 中的第一个服务器
+}
+
+// StalenessConfig 提供商接口故障时继续输出上次成功获取的数据，超过 StaleAfter 标记为过时，超过 MaxStaleness 后不再输出
+type StalenessConfig struct {
+	StaleAfter   string `yaml:"stale_after"`   // 可选，默认 2m
+	MaxStaleness string `yaml:"max_staleness"` // 可选，默认 1h
+}
+
+// StaleAfterDuration 返回标记为过时的时长
+func (c StalenessConfig) StaleAfterDuration() time.Duration {
+	return parseDuration(c.StaleAfter, 2*time.Minute)
+}
+
+// MaxStalenessDuration 返回最大过时时长
+func (c StalenessConfig) MaxStalenessDuration() time.Duration {
+	return parseDuration(c.MaxStaleness, time.Hour)
+}
+
+func parseDuration(s string, defaultValue time.Duration) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return defaultValue
+	}
+	return d
+}
+
+// ChangeJournalConfig 记录变更日志配置
+type ChangeJournalConfig struct {
+	Path string `yaml:"path"` // 可选，JSONL 文件路径，为空时仅在内存中保留最近 1000 条变更
+}
+
+// NotificationConfig 通知配置，事件按 ThrottleWindow 合并后发送，相同事件在 DedupWindow 内只发送一次
+type NotificationConfig struct {
+	DedupWindow      string             `yaml:"dedup_window"`       // 可选，默认 24h
+	ThrottleWindow   string             `yaml:"throttle_window"`    // 可选，默认 1m
+	DomainExpiryDays int                `yaml:"domain_expiry_days"` // 可选，域名剩余天数不超过该值时通知，默认 30
+	CertExpiryDays   int                `yaml:"cert_expiry_days"`   // 可选，证书剩余天数不超过该值时通知，默认 15
+	Sinks            []NotificationSink `yaml:"sinks"`
+}
+
+// DedupWindowDuration 返回相同事件不重复发送的时长
+func (c NotificationConfig) DedupWindowDuration() time.Duration {
+	return parseDuration(c.DedupWindow, 24*time.Hour)
+}
+
+// ThrottleWindowDuration 返回合并发送事件的间隔
+func (c NotificationConfig) ThrottleWindowDuration() time.Duration {
+	return parseDuration(c.ThrottleWindow, time.Minute)
+}
+
+// NotificationSink 通知渠道
+type NotificationSink struct {
+	Name     string            `yaml:"name"`
+	Type     string            `yaml:"type"`     // webhook、dingtalk、feishu、wecom、slack 或 email
+	URL      string            `yaml:"url"`      // 机器人或 webhook 地址
+	Secret   string            `yaml:"secret"`   // 可选，钉钉、飞书机器人的加签密钥
+	Headers  map[string]string `yaml:"headers"`  // 可选，webhook 的请求头
+	Template string            `yaml:"template"` // 可选，Go text/template 模板，可使用 .Events
+	Events   []string          `yaml:"events"`   // 可选，只发送指定类型的事件，为空时发送全部
+	SMTPHost string            `yaml:"smtp_host"`
+	SMTPPort int               `yaml:"smtp_port"` // 465 时使用 TLS 连接，其他端口支持 STARTTLS
+	Username string            `yaml:"username"`
+	Password string            `yaml:"password"`
+	From     string            `yaml:"from"`
+	To       []string          `yaml:"to"`
 }
 
 // Config 表示配置文件的结构
 type Configuration struct {
-	CustomRecords  []string `yaml:"custom_records"`
+	CustomRecords  []string               `yaml:"custom_records"`
+	MetricsSchema  string                 `yaml:"metrics_schema"` // 指标模型，legacy(默认)、v2 或 both
+	Storage        StorageConfig          `yaml:"storage"`
+	Staleness      StalenessConfig        `yaml:"staleness"`
+	ChangeJournal  ChangeJournalConfig    `yaml:"change_journal"`
+	Notifications  NotificationConfig     `yaml:"notifications"`
+	Labels         LabelConfig            `yaml:"labels"`
+	ProbeModules   map[string]ProbeModule `yaml:"probe_modules"`
+	Plugins        map[string]Plugin      `yaml:"plugins"`
 	CloudProviders map[string]struct {
 		Accounts []map[string]string `yaml:"accounts"`
 	} `yaml:"cloud_providers"`
@@ -69,18 +231,36 @@ func LoadConfig() *Configuration {
 		if err != nil {
 			logger.Fatal("unmarshal config file failed: ", err)
 		}
+		switch Config.MetricsSchema {
+		case "", MetricsSchemaLegacy, MetricsSchemaV2, MetricsSchemaBoth:
+		default:
+			logger.Fatal("unsupported metrics_schema, must be one of legacy, v2, both: ", Config.MetricsSchema)
+		}
 	})
 	return Config
 }
 
 // InitCache 初始化缓存
 func InitCache() {
+	var (
+		storage   StorageConfig
+		staleness StalenessConfig
+	)
+	if Config != nil {
+		storage = Config.Storage
+		staleness = Config.Staleness
+	}
+	// 内存缓存需保留到最大过时时长，才能在提供商接口故障期间继续输出上次的数据
+	lifetime := staleness.MaxStalenessDuration()
+	if lifetime < 5*time.Minute {
+		lifetime = 5 * time.Minute
+	}
 	var err error
-	Cache, err = bigcache.New(context.Background(), bigcache.DefaultConfig(5*time.Minute))
+	Cache, err = NewStore(storage, "cache", lifetime)
 	if err != nil {
 		logger.Fatal("init cache failed: ", err)
 	}
-	CertCache, err = bigcache.New(context.Background(), bigcache.DefaultConfig(25*time.Hour))
+	CertCache, err = NewStore(storage, "cert", 25*time.Hour)
 	if err != nil {
 		logger.Fatal("init cache failed: ", err)
 	}
@@ -0,0 +1,152 @@
+package public
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+	"github.com/redis/go-redis/v9"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	StorageMemory string = "memory"
+	StorageBbolt  string = "bbolt"
+	StorageRedis  string = "redis"
+)
+
+// ErrEntryNotFound 各存储在键不存在时统一返回该错误
+var ErrEntryNotFound = bigcache.ErrEntryNotFound
+
+// Store 缓存存储，域名、记录及证书数据均以 JSON 序列化后的字节存取
+type Store interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte) error
+}
+
+// StorageConfig 缓存存储配置，bbolt 与 redis 为持久化存储，重启后可直接使用上次获取的数据
+type StorageConfig struct {
+	Type     string `yaml:"type"`     // memory(默认)、bbolt 或 redis
+	Path     string `yaml:"path"`     // bbolt，数据文件路径，默认 data/cache.db
+	Address  string `yaml:"address"`  // redis，地址，默认 127.0.0.1:6379
+	Password string `yaml:"password"` // redis，可选
+	DB       int    `yaml:"db"`       // redis，可选
+	Prefix   string `yaml:"prefix"`   // redis，可选，键前缀，默认 cloud_dns_exporter
+}
+
+// Persistent 是否为持久化存储
+func (c StorageConfig) Persistent() bool {
+	return c.Type == StorageBbolt || c.Type == StorageRedis
+}
+
+// NewStore 按配置创建存储，name 用于区分同一后端中的不同缓存，lifetime 仅对内存存储生效
+// 持久化存储中的数据不会过期，总是保留最近一次成功获取的数据
+func NewStore(cfg StorageConfig, name string, lifetime time.Duration) (Store, error) {
+	switch cfg.Type {
+	case "", StorageMemory:
+		return bigcache.New(context.Background(), bigcache.DefaultConfig(lifetime))
+	case StorageBbolt:
+		return newBoltStore(cfg, name)
+	case StorageRedis:
+		return newRedisStore(cfg, name)
+	}
+	return nil, fmt.Errorf("unsupported storage type %q", cfg.Type)
+}
+
+// boltDBs 同一数据文件只能打开一次，不同缓存使用各自的 bucket
+var boltDBs = make(map[string]*bolt.DB)
+
+type boltStore struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+func newBoltStore(cfg StorageConfig, name string) (*boltStore, error) {
+	path := cfg.Path
+	if path == "" {
+		path = "data/cache.db"
+	}
+	db, ok := boltDBs[path]
+	if !ok {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		var err error
+		db, err = bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+		if err != nil {
+			return nil, err
+		}
+		boltDBs[path] = db
+	}
+	s := &boltStore{db: db, bucket: []byte(name)}
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(s.bucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *boltStore) Get(key string) (value []byte, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(s.bucket).Get([]byte(key))
+		if v == nil {
+			return ErrEntryNotFound
+		}
+		// bbolt 返回的切片仅在事务内有效
+		value = append([]byte(nil), v...)
+		return nil
+	})
+	return value, err
+}
+
+func (s *boltStore) Set(key string, value []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Put([]byte(key), value)
+	})
+}
+
+type redisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func newRedisStore(cfg StorageConfig, name string) (*redisStore, error) {
+	address := cfg.Address
+	if address == "" {
+		address = "127.0.0.1:6379"
+	}
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = "cloud_dns_exporter"
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     address,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+	return &redisStore{client: client, prefix: prefix + ":" + name + ":"}, nil
+}
+
+func (s *redisStore) Get(key string) ([]byte, error) {
+	value, err := s.client.Get(context.Background(), s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrEntryNotFound
+	}
+	return value, err
+}
+
+func (s *redisStore) Set(key string, value []byte) error {
+	return s.client.Set(context.Background(), s.prefix+key, value, 0).Err()
+}